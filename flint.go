@@ -89,6 +89,8 @@ static void compat_flint_randclear(flint_rand_t state) {
 import "C"
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 	"runtime"
 	"unsafe"
@@ -388,24 +390,49 @@ func (z *Fmpz) BitLen() int {
 	return int(C.fmpz_sizeinbase(&z.i[0], 2))
 }
 
-// Lsh left shifts an Fmpz z by an arbitrary number of bits and returns it.
+// Lsh left shifts an Fmpz z by an arbitrary number of bits and returns it. The sign of z is
+// preserved so this is equivalent to z * 2^bits.
 func (z *Fmpz) Lsh(bits int) *Fmpz {
 	z.doinit()
-	// We proxy this through the std library big.Int type since Flint has no
-	// method for bit shifting.
-	i := new(big.Int).SetBytes(z.Bytes())
-	i = i.Lsh(i, uint(bits))
-	return z.SetBytes(i.Bytes())
+	if bits < 0 {
+		panic("Negative shift count")
+	}
+	C.fmpz_mul_2exp(&z.i[0], &z.i[0], C.ulong(bits))
+	return z
 }
 
-// Rsh right shifts an Fmpz z by an arbitrary number of bits and returns it.
+// Rsh right shifts an Fmpz z by an arbitrary number of bits and returns it. The quotient is
+// rounded towards -infinity, so negative values behave as an arithmetic shift of their two's
+// complement representation, matching big.Int.
 func (z *Fmpz) Rsh(bits int) *Fmpz {
 	z.doinit()
-	// We proxy this through the std library big.Int type since Flint has no
-	// method for bit shifting.
-	i := new(big.Int).SetBytes(z.Bytes())
-	i = i.Rsh(i, uint(bits))
-	return z.SetBytes(i.Bytes())
+	if bits < 0 {
+		panic("Negative shift count")
+	}
+	C.fmpz_fdiv_q_2exp(&z.i[0], &z.i[0], C.ulong(bits))
+	return z
+}
+
+// RshCeil right shifts an Fmpz z by an arbitrary number of bits rounding the quotient towards
+// +infinity and returns it.
+func (z *Fmpz) RshCeil(bits int) *Fmpz {
+	z.doinit()
+	if bits < 0 {
+		panic("Negative shift count")
+	}
+	C.fmpz_cdiv_q_2exp(&z.i[0], &z.i[0], C.ulong(bits))
+	return z
+}
+
+// RshTrunc right shifts an Fmpz z by an arbitrary number of bits rounding the quotient towards
+// zero and returns it. This shifts the absolute value and keeps the sign.
+func (z *Fmpz) RshTrunc(bits int) *Fmpz {
+	z.doinit()
+	if bits < 0 {
+		panic("Negative shift count")
+	}
+	C.fmpz_tdiv_q_2exp(&z.i[0], &z.i[0], C.ulong(bits))
+	return z
 }
 
 // Xor sets z to the bitwise exclusive or of a and b and returns z.
@@ -417,6 +444,64 @@ func (z *Fmpz) Xor(a, b *Fmpz) *Fmpz {
 	return z
 }
 
+// Or sets z to the bitwise or of x and y and returns z.
+func (z *Fmpz) Or(x, y *Fmpz) *Fmpz {
+	x.doinit()
+	y.doinit()
+	z.doinit()
+	C.fmpz_or(&z.i[0], &x.i[0], &y.i[0])
+	return z
+}
+
+// Not sets z to the bitwise complement of x, which is -x-1 in two's complement, and returns z.
+func (z *Fmpz) Not(x *Fmpz) *Fmpz {
+	x.doinit()
+	z.doinit()
+	C.fmpz_complement(&z.i[0], &x.i[0])
+	return z
+}
+
+// SetBit sets bit index i of z to 1 and returns z.
+func (z *Fmpz) SetBit(i int) *Fmpz {
+	z.doinit()
+	C.fmpz_setbit(&z.i[0], C.ulong(i))
+	return z
+}
+
+// ClrBit sets bit index i of z to 0 and returns z.
+func (z *Fmpz) ClrBit(i int) *Fmpz {
+	z.doinit()
+	C.fmpz_clrbit(&z.i[0], C.ulong(i))
+	return z
+}
+
+// ComBit complements bit index i of z and returns z.
+func (z *Fmpz) ComBit(i int) *Fmpz {
+	z.doinit()
+	C.fmpz_combit(&z.i[0], C.ulong(i))
+	return z
+}
+
+// PopCount returns the number of 1 bits in z. A negative z has infinitely many 1 bits in two's
+// complement, so -1 is returned.
+func (z *Fmpz) PopCount() int {
+	z.doinit()
+	if z.Sign() < 0 {
+		return -1
+	}
+	return int(C.fmpz_popcnt(&z.i[0]))
+}
+
+// TrailingZeros returns the number of consecutive least significant zero bits of |z|, which is
+// the same in two's complement. The result for 0 is 0.
+func (z *Fmpz) TrailingZeros() int {
+	z.doinit()
+	if z.Sign() == 0 {
+		return 0
+	}
+	return int(C.fmpz_val2(&z.i[0]))
+}
+
 // Sign returns:
 //
 //	-1 if x <  0
@@ -543,6 +628,129 @@ func (z *Fmpz) Bytes() []byte {
 	return b[0:n]
 }
 
+// FillBytes sets buf to the absolute value of z as a zero-extended big-endian byte slice, as in
+// the I2OSP primitive, and returns buf. An error is returned if the value does not fit in buf.
+func (z *Fmpz) FillBytes(buf []byte) ([]byte, error) {
+	z.doinit()
+	n := (z.BitLen() + 7) / 8
+	if n > len(buf) {
+		return nil, errors.New("FillBytes: value does not fit in buffer")
+	}
+	for i := range buf {
+		buf[i] = 0
+	}
+	if n == 0 {
+		return buf, nil
+	}
+
+	zm := new(Mpz)
+	zm.GetMpz(z)
+	cn := C.size_t(n)
+	C.mpz_export(unsafe.Pointer(&buf[len(buf)-n]), &cn, 1, 1, 1, 0, &zm.i[0])
+	return buf, nil
+}
+
+// SetBytesLE interprets buf as the bytes of a little-endian unsigned integer, sets z to that
+// value, and returns z.
+func (z *Fmpz) SetBytesLE(buf []byte) *Fmpz {
+	if len(buf) == 0 {
+		return z.SetInt64(0)
+	}
+	zm := new(Mpz)
+	zm.mpzDoinit()
+	C.mpz_import(&zm.i[0], C.size_t(len(buf)), -1, 1, 1, 0, unsafe.Pointer(&buf[0]))
+	z.SetMpz(zm)
+	return z
+}
+
+// BytesLE returns the absolute value of z as a little-endian byte slice.
+func (z *Fmpz) BytesLE() []byte {
+	zm := new(Mpz)
+	zm.GetMpz(z)
+	b := make([]byte, 1+(z.BitLen()+7)/8)
+	n := C.size_t(len(b))
+	C.mpz_export(unsafe.Pointer(&b[0]), &n, -1, 1, 1, 0, &zm.i[0])
+	return b[0:n]
+}
+
+// SetSignedBytes interprets buf as the bytes of a big-endian two's complement signed integer,
+// sets z to that value, and returns z. An empty buf is 0.
+func (z *Fmpz) SetSignedBytes(buf []byte) *Fmpz {
+	z.SetBytes(buf)
+	if len(buf) > 0 && buf[0]&0x80 != 0 {
+		z.Sub(z, NewFmpz(1).Lsh(8*len(buf)))
+	}
+	return z
+}
+
+// SignedBytes returns z as the shortest big-endian two's complement byte slice that holds its
+// value and sign. The encoding of 0 is a single zero byte.
+func (z *Fmpz) SignedBytes() []byte {
+	z.doinit()
+	m := new(Fmpz).Set(z)
+	if z.Sign() < 0 {
+		m.Not(z)
+	}
+	// One extra bit for the sign.
+	n := (m.BitLen() + 8) / 8
+
+	if z.Sign() < 0 {
+		m.Add(z, NewFmpz(1).Lsh(8*n))
+	}
+	b, _ := m.FillBytes(make([]byte, n))
+	return b
+}
+
+// wordSize is the size in bytes of a big.Word.
+const wordSize = C.size_t(unsafe.Sizeof(big.Word(0)))
+
+// SetBigInt sets z to the value of x and returns z. The limbs of x are copied directly without
+// an intermediate string or byte slice.
+func (z *Mpz) SetBigInt(x *big.Int) *Mpz {
+	z.mpzDoinit()
+	w := x.Bits()
+	if len(w) == 0 {
+		return z.SetMpzInt64(0)
+	}
+	C.mpz_import(&z.i[0], C.size_t(len(w)), -1, wordSize, 0, 0, unsafe.Pointer(&w[0]))
+	if x.Sign() < 0 {
+		C.mpz_neg(&z.i[0], &z.i[0])
+	}
+	return z
+}
+
+// BigInt returns the value of z as a new big.Int.
+func (z *Mpz) BigInt() *big.Int {
+	z.mpzDoinit()
+	sign := z.Cmp(NewMpz(0))
+	if sign == 0 {
+		return new(big.Int)
+	}
+	bits := int(C.mpz_sizeinbase(&z.i[0], 2))
+	w := make([]big.Word, (bits+int(wordSize)*8-1)/(int(wordSize)*8))
+	n := C.size_t(len(w))
+	C.mpz_export(unsafe.Pointer(&w[0]), &n, -1, wordSize, 0, 0, &z.i[0])
+	x := new(big.Int).SetBits(w[:n])
+	if sign < 0 {
+		x.Neg(x)
+	}
+	return x
+}
+
+// SetBigInt sets z to the value of x including its sign and returns z.
+func (z *Fmpz) SetBigInt(x *big.Int) *Fmpz {
+	z.doinit()
+	z.SetMpz(new(Mpz).SetBigInt(x))
+	return z
+}
+
+// BigInt returns the value of z including its sign as a new big.Int.
+func (z *Fmpz) BigInt() *big.Int {
+	zm := new(Mpz)
+	zm.GetMpz(z)
+	return zm.BigInt()
+}
+
 /*
  * Arithmetic
  */
@@ -838,7 +1046,7 @@ func (z *Fmpz) DivMod(x, y, m *Fmpz) (*Fmpz, *Fmpz) {
 // ModInverse sets z to the inverse of x modulo y and returns z.
 // The value of y may not be 0 otherwise an exception results. If the
 // inverse exists the return value will be non-zero, otherwise the return value
-// will be 0 and the value of f undefined.
+// will be 0 and the value of f undefined. Use ModInverseErr to detect a missing inverse.
 func (z *Fmpz) ModInverse(x, y *Fmpz) *Fmpz {
 	x.doinit()
 	y.doinit()
@@ -848,6 +1056,36 @@ func (z *Fmpz) ModInverse(x, y *Fmpz) *Fmpz {
 	return z
 }
 
+// NotInvertibleError is returned by ModInverseErr when x has no inverse modulo n. GCD holds
+// gcd(x, n), which is a non-trivial factor of n whenever it is not n itself.
+type NotInvertibleError struct {
+	X, N, GCD *Fmpz
+}
+
+// Error implements the error interface.
+func (e *NotInvertibleError) Error() string {
+	return fmt.Sprintf("%v is not invertible modulo %v: gcd is %v", e.X, e.N, e.GCD)
+}
+
+// ModInverseErr sets z to the inverse of x modulo y and returns z. If the inverse does not exist,
+// z is unchanged and a *NotInvertibleError carrying gcd(x, y) is returned, and if y is 0 a plain
+// error is returned.
+func (z *Fmpz) ModInverseErr(x, y *Fmpz) (*Fmpz, error) {
+	x.doinit()
+	y.doinit()
+	z.doinit()
+	if y.IsZero() {
+		return nil, errors.New("ModInverseErr: modulus is zero")
+	}
+
+	g := new(Fmpz).GCD(x, y)
+	if g.Cmp(NewFmpz(1)) != 0 {
+		return nil, &NotInvertibleError{X: new(Fmpz).Set(x), N: new(Fmpz).Set(y), GCD: g}
+	}
+	C.fmpz_invmod(&z.i[0], &x.i[0], &y.i[0])
+	return z, nil
+}
+
 // NegMod Sets z to −x (mod y), assuming x is reduced modulo y.
 func (z *Fmpz) NegMod(x, y *Fmpz) *Fmpz {
 	x.doinit()
@@ -985,6 +1223,21 @@ func (z *Fmpz) GCD(g, h *Fmpz) *Fmpz {
 	return z
 }
 
+// XGCD sets z to the greatest common divisor g = gcd(a, b) and returns g together with Bezout
+// coefficients s and t satisfying g = a*s + b*t. The result g is always nonnegative and the
+// coefficients are the minimal ones computed by FLINT.
+func (z *Fmpz) XGCD(a, b *Fmpz) (*Fmpz, *Fmpz, *Fmpz) {
+	a.doinit()
+	b.doinit()
+	z.doinit()
+	s := new(Fmpz)
+	s.doinit()
+	t := new(Fmpz)
+	t.doinit()
+	C.fmpz_xgcd(&z.i[0], &s.i[0], &t.i[0], &a.i[0], &b.i[0])
+	return z, s, t
+}
+
 // Lcm sets f to the least common multiple of g and h. The result is always nonnegative, even
 // if one of g and h is negative.
 func (z *Fmpz) Lcm(g, h *Fmpz) *Fmpz {
@@ -1036,6 +1289,88 @@ func (z *Fmpz) Root(x *Fmpz, y int32) *Fmpz {
 	return z
 }
 
+// SqrtRem sets z to the truncated integer part of the square root of x and r to the remainder
+// x - z^2, and returns the pair (z, r). x must be non-negative. The root is exact if r is zero.
+func (z *Fmpz) SqrtRem(x, r *Fmpz) (*Fmpz, *Fmpz) {
+	x.doinit()
+	r.doinit()
+	z.doinit()
+	C.fmpz_sqrtrem(&z.i[0], &r.i[0], &x.i[0])
+	return z, r
+}
+
+// RootRem sets z to the truncated integer part of the yth root of x and r to the remainder
+// x - z^y, and returns the pair (z, r). If y is even x must be non-negative. The root is exact if
+// r is zero.
+func (z *Fmpz) RootRem(x *Fmpz, y int32, r *Fmpz) (*Fmpz, *Fmpz) {
+	x.doinit()
+	r.doinit()
+	z.doinit()
+	// Compute the root into a temporary in case r aliases x.
+	t := new(Fmpz)
+	t.doinit()
+	C.fmpz_root(&t.i[0], &x.i[0], C.slong(y))
+	p := new(Fmpz).ExpXI(t, int(y))
+	r.Sub(x, p)
+	z.Set(t)
+	return z, r
+}
+
+// CeilSqrt sets z to the smallest integer whose square is at least x and returns z. x must be
+// non-negative.
+func (z *Fmpz) CeilSqrt(x *Fmpz) *Fmpz {
+	r := new(Fmpz)
+	z.SqrtRem(x, r)
+	if r.Sign() != 0 {
+		z.AddI(1)
+	}
+	return z
+}
+
+// CeilRoot sets z to the ceiling of the yth root of x and returns z. If y is even x must be
+// non-negative.
+func (z *Fmpz) CeilRoot(x *Fmpz, y int32) *Fmpz {
+	r := new(Fmpz)
+	z.RootRem(x, y, r)
+	// The truncated root of a negative x is already its ceiling.
+	if r.Sign() > 0 {
+		z.AddI(1)
+	}
+	return z
+}
+
+// IsSquare returns true if z is a perfect square.
+func (z *Fmpz) IsSquare() bool {
+	z.doinit()
+	return C.fmpz_is_square(&z.i[0]) != 0
+}
+
+// IsPerfectPower returns the base b and largest exponent k > 1 such that z = b^k, or (nil, 0) if
+// z is not a perfect power. Negative z are permitted in which case k is odd. As in FLINT -1, 0 and
+// 1 are considered perfect powers of themselves.
+func (z *Fmpz) IsPerfectPower() (*Fmpz, int) {
+	z.doinit()
+	b := new(Fmpz)
+	b.doinit()
+	k := int(C.fmpz_is_perfect_power(&b.i[0], &z.i[0]))
+	if k == 0 {
+		return nil, 0
+	}
+
+	// FLINT does not guarantee the largest exponent so keep reducing the base.
+	r := new(Fmpz)
+	r.doinit()
+	for new(Fmpz).Abs(b).Cmp(NewFmpz(1)) > 0 {
+		j := int(C.fmpz_is_perfect_power(&r.i[0], &b.i[0]))
+		if j == 0 {
+			break
+		}
+		b.Set(r)
+		k *= j
+	}
+	return b, k
+}
+
 // Arbitrary precision primality testing and factorization.
 
 // IsStrongProbabPrime returns 1 if z is a strong probable prime to base a, otherwise it returns 0
@@ -1096,6 +1431,17 @@ func (z *Fmpz) IsProbabPrimePseudosquare() int {
 	return int(C.fmpz_is_prime_pseudosquare(&z.i[0]))
 }
 
+// IsPrime proves or disproves the primality of z, returning 1 if z is proven prime and 0 if z
+// is composite (or less than 2). Unlike the IsProbabPrime family the answer is never
+// probabilistic: after ruling out small factors the Pocklington, Morrison-Brillhart-Selfridge
+// tests are tried and, if these fail to give a proof, the APR-CL test is run. There is no
+// limit on the size of z although very large inputs may take a long time.
+// See ProvePrime for a primality proof that can be checked independently.
+func (z *Fmpz) IsPrime() int {
+	z.doinit()
+	return int(C.fmpz_is_prime(&z.i[0]))
+}
+
 // LucasChain Given V0 = 2, V1 = A compute Vm, Vm+1 (mod n) from the recurrences Vj = AVj−1 −
 // Vj−2 (mod n).
 func (z *Fmpz) LucasChain(v2, a, m, n *Fmpz) {
@@ -1107,6 +1453,53 @@ func (z *Fmpz) LucasChain(v2, a, m, n *Fmpz) {
 	C.fmpz_lucas_chain(&z.i[0], &v2.i[0], &a.i[0], &m.i[0], &n.i[0])
 }
 
+// LucasUV sets z to U_n (mod m) and v to V_n (mod m) for the Lucas sequences with parameters p
+// and q, defined by U0 = 0, U1 = 1, V0 = 2, V1 = p and Xj = pXj−1 − qXj−2, and returns the pair
+// (z, v). The index n must be non-negative and the modulus m positive. No inversion modulo m is
+// required so any modulus may be used.
+func (z *Fmpz) LucasUV(v, p, q, n, m *Fmpz) (*Fmpz, *Fmpz) {
+	z.doinit()
+	v.doinit()
+	p.doinit()
+	q.doinit()
+	n.doinit()
+	m.doinit()
+	if n.Sign() < 0 {
+		panic("LucasUV: negative index")
+	}
+
+	// Walk the bits of n maintaining (a, b) = (U_k, U_k+1) using the doubling formulas
+	//	U_2k   = U_k(2U_k+1 − pU_k)
+	//	U_2k+1 = U_k+1^2 − qU_k^2
+	//	U_2k+2 = pU_2k+1 − qU_2k
+	a := NewFmpz(0)
+	b := new(Fmpz).Mod(NewFmpz(1), m)
+	t := new(Fmpz)
+	u := new(Fmpz)
+	for i := n.Bits() - 1; i >= 0; i-- {
+		t.Mul(p, a)
+		t.Sub(new(Fmpz).Add(b, b), t)
+		t.Mul(t, a).ModZ(m)
+		u.Mul(b, b)
+		b.Mul(a, a).MulZ(q)
+		b.Sub(u, b).ModZ(m)
+		a.Set(t)
+		if n.TstBit(i) == 1 {
+			t.Mul(p, b)
+			t.Sub(t, u.Mul(q, a)).ModZ(m)
+			a.Set(b)
+			b.Set(t)
+		}
+	}
+
+	// V_n = 2U_n+1 − pU_n.
+	t.Mul(p, a)
+	t.Sub(b.Add(b, b), t).ModZ(m)
+	z.Set(a)
+	v.Set(t)
+	return z, v
+}
+
 // Bits returns the number of bits required to store the absolute value of z. If z is 0 then 0 is
 // returned.
 func (z *Fmpz) Bits() int {
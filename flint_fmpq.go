@@ -4,6 +4,7 @@ package goflint
 #include <flint/flint.h>
 #include <flint/fmpz.h>
 #include <flint/fmpq.h>
+#include <flint/fmpz_vec.h>
 #include <gmp.h>
 #include <stdlib.h>
 
@@ -15,11 +16,18 @@ fmpz *_fmpq_numref(fmpq_t op) {
 fmpz *_fmpq_denref(fmpq_t op) {
     return fmpq_denref(op);
 }
+fmpz *_fmpz_vec_entry(fmpz *v, slong i) {
+    return v + i;
+}
 
 */
 import "C"
 import (
+	"math"
+	"math/big"
 	"runtime"
+	"strconv"
+	"strings"
 	"unsafe"
 )
 
@@ -149,3 +157,521 @@ func (q *Fmpq) MulRational(o *Fmpq, x *Fmpz) *Fmpq {
 	C.fmpq_mul_fmpz(&q.i[0], &o.i[0], &x.i[0])
 	return q
 }
+
+// SetBigRat sets q to the value of x and returns q.
+func (q *Fmpq) SetBigRat(x *big.Rat) *Fmpq {
+	return q.SetFmpqFraction(new(Fmpz).SetBigInt(x.Num()), new(Fmpz).SetBigInt(x.Denom()))
+}
+
+// BigRat returns the value of q as a new big.Rat.
+func (q *Fmpq) BigRat() *big.Rat {
+	num, den := q.fmpqParts()
+	return new(big.Rat).SetFrac(num.BigInt(), den.BigInt())
+}
+
+// fmpqParts returns copies of the numerator and denominator of q.
+func (q *Fmpq) fmpqParts() (*Fmpz, *Fmpz) {
+	q.fmpqDoinit()
+	num := new(Fmpz)
+	num.doinit()
+	C.fmpz_set(&num.i[0], C._fmpq_numref(&q.i[0]))
+	den := new(Fmpz)
+	den.doinit()
+	C.fmpz_set(&den.i[0], C._fmpq_denref(&q.i[0]))
+	return num, den
+}
+
+// Reconstruct sets q to the rational n/d with |n|, d <= sqrt((m-1)/2) that is congruent to a
+// modulo m, i.e. n = a*d (mod m), and returns q and true. It is the inverse of
+// Fmpz.ModRational. If m <= 1 or no such rational exists q is left unchanged and nil and false
+// are returned.
+func (q *Fmpq) Reconstruct(a, m *Fmpz) (*Fmpq, bool) {
+	a.doinit()
+	m.doinit()
+	q.fmpqDoinit()
+	if m.Cmp(NewFmpz(1)) <= 0 {
+		return nil, false
+	}
+
+	r := new(Fmpq)
+	r.fmpqDoinit()
+	if C.fmpq_reconstruct_fmpz(&r.i[0], &new(Fmpz).Mod(a, m).i[0], &m.i[0]) == 0 {
+		return nil, false
+	}
+	C.fmpq_set(&q.i[0], &r.i[0])
+	return q, true
+}
+
+// ReconstructBounded sets q to the rational n/d with |n| <= nBound and 0 < d <= dBound that is
+// congruent to a modulo m and returns q and true. The bounds must be positive and satisfy
+// 2*nBound*dBound < m so that the answer is unique, otherwise, or if no such rational exists,
+// q is left unchanged and nil and false are returned.
+func (q *Fmpq) ReconstructBounded(a, m, nBound, dBound *Fmpz) (*Fmpq, bool) {
+	a.doinit()
+	m.doinit()
+	nBound.doinit()
+	dBound.doinit()
+	q.fmpqDoinit()
+	if nBound.Sign() <= 0 || dBound.Sign() <= 0 {
+		return nil, false
+	}
+	if new(Fmpz).Mul(nBound, dBound).Lsh(1).Cmp(m) >= 0 {
+		return nil, false
+	}
+
+	r := new(Fmpq)
+	r.fmpqDoinit()
+	if C.fmpq_reconstruct_fmpz_2(&r.i[0], &new(Fmpz).Mod(a, m).i[0], &m.i[0], &nBound.i[0], &dBound.i[0]) == 0 {
+		return nil, false
+	}
+	C.fmpq_set(&q.i[0], &r.i[0])
+	return q, true
+}
+
+// ContinuedFraction returns the first maxTerms terms of the simple continued fraction expansion
+// [c0; c1, c2, ...] of q, or all of the terms if maxTerms <= 0 or the expansion is shorter. The
+// first term is floor(q) and every later term is positive.
+func (q *Fmpq) ContinuedFraction(maxTerms int) []*Fmpz {
+	q.fmpqDoinit()
+	n := C.fmpq_cfrac_bound(&q.i[0])
+	if maxTerms > 0 && C.slong(maxTerms) < n {
+		n = C.slong(maxTerms)
+	}
+
+	c := C._fmpz_vec_init(n)
+	defer C._fmpz_vec_clear(c, n)
+	rem := new(Fmpq)
+	rem.fmpqDoinit()
+	got := C.fmpq_get_cfrac(c, &rem.i[0], &q.i[0], n)
+
+	terms := make([]*Fmpz, int(got))
+	for i := range terms {
+		terms[i] = new(Fmpz)
+		terms[i].doinit()
+		C.fmpz_set(&terms[i].i[0], C._fmpz_vec_entry(c, C.slong(i)))
+	}
+	return terms
+}
+
+// SetContinuedFraction sets q to the value of the continued fraction [c0; c1, c2, ...] given by
+// terms and returns q. Every term after the first must be positive. An empty list of terms
+// sets q to 0.
+func (q *Fmpq) SetContinuedFraction(terms []*Fmpz) *Fmpq {
+	q.fmpqDoinit()
+	if len(terms) == 0 {
+		C.fmpq_zero(&q.i[0])
+		return q
+	}
+
+	n := C.slong(len(terms))
+	c := C._fmpz_vec_init(n)
+	defer C._fmpz_vec_clear(c, n)
+	for i, t := range terms {
+		t.doinit()
+		C.fmpz_set(C._fmpz_vec_entry(c, C.slong(i)), &t.i[0])
+	}
+	C.fmpq_set_cfrac(&q.i[0], c, n)
+	return q
+}
+
+// Convergents iterates lazily over the convergents of the continued fraction of a rational,
+// computing one term of the expansion per call to Next.
+type Convergents struct {
+	num, den *Fmpz // the remaining value num/den still to be expanded
+	h, hPrev *Fmpz // numerators of the last two convergents
+	k, kPrev *Fmpz // denominators of the last two convergents
+	term     *Fmpz
+}
+
+// Convergents returns an iterator over the convergents of q. The iterator works on a copy of q
+// so later changes to q do not affect it.
+func (q *Fmpq) Convergents() *Convergents {
+	num, den := q.fmpqParts()
+	return &Convergents{
+		num:   num,
+		den:   den,
+		h:     NewFmpz(1),
+		hPrev: NewFmpz(0),
+		k:     NewFmpz(0),
+		kPrev: NewFmpz(1),
+	}
+}
+
+// Next returns the next convergent and true, or nil and false once every convergent has been
+// returned. The last convergent is equal to the rational being expanded.
+func (c *Convergents) Next() (*Fmpq, bool) {
+	if c.den.IsZero() {
+		return nil, false
+	}
+
+	t, r := new(Fmpz).DivMod(c.num, c.den, new(Fmpz))
+	c.num, c.den = c.den, r
+	c.term = t
+
+	c.h, c.hPrev = new(Fmpz).Add(new(Fmpz).Mul(t, c.h), c.hPrev), c.h
+	c.k, c.kPrev = new(Fmpz).Add(new(Fmpz).Mul(t, c.k), c.kPrev), c.k
+	return NewFmpqFmpz(c.h, c.k), true
+}
+
+// Term returns the continued fraction term used to form the convergent last returned by Next,
+// or nil if Next has not been called.
+func (c *Convergents) Term() *Fmpz {
+	if c.term == nil {
+		return nil
+	}
+	return new(Fmpz).Set(c.term)
+}
+
+// Num returns a copy of the numerator of q as an Fmpz. Unlike NumRef the value is not limited to
+// the size of an int.
+func (q *Fmpq) Num() *Fmpz {
+	num, _ := q.fmpqParts()
+	return num
+}
+
+// Den returns a copy of the denominator of q as an Fmpz, which is always positive. Unlike DenRef
+// the value is not limited to the size of an int.
+func (q *Fmpq) Den() *Fmpz {
+	_, den := q.fmpqParts()
+	return den
+}
+
+// Set sets q to x and returns q.
+func (q *Fmpq) Set(x *Fmpq) *Fmpq {
+	x.fmpqDoinit()
+	q.fmpqDoinit()
+	C.fmpq_set(&q.i[0], &x.i[0])
+	return q
+}
+
+// Sign returns:
+//
+//	-1 if q <  0
+//	 0 if q == 0
+//	+1 if q >  0
+func (q *Fmpq) Sign() int {
+	q.fmpqDoinit()
+	return int(C.fmpq_sgn(&q.i[0]))
+}
+
+// Add sets q to the sum x+y and returns q.
+func (q *Fmpq) Add(x, y *Fmpq) *Fmpq {
+	x.fmpqDoinit()
+	y.fmpqDoinit()
+	q.fmpqDoinit()
+	C.fmpq_add(&q.i[0], &x.i[0], &y.i[0])
+	return q
+}
+
+// Sub sets q to the difference x-y and returns q.
+func (q *Fmpq) Sub(x, y *Fmpq) *Fmpq {
+	x.fmpqDoinit()
+	y.fmpqDoinit()
+	q.fmpqDoinit()
+	C.fmpq_sub(&q.i[0], &x.i[0], &y.i[0])
+	return q
+}
+
+// Mul sets q to the product x*y and returns q.
+func (q *Fmpq) Mul(x, y *Fmpq) *Fmpq {
+	x.fmpqDoinit()
+	y.fmpqDoinit()
+	q.fmpqDoinit()
+	C.fmpq_mul(&q.i[0], &x.i[0], &y.i[0])
+	return q
+}
+
+// Div sets q to the quotient x/y for y != 0 and returns q.
+// If y == 0, a division-by-zero run-time panic occurs.
+func (q *Fmpq) Div(x, y *Fmpq) *Fmpq {
+	x.fmpqDoinit()
+	y.fmpqDoinit()
+	q.fmpqDoinit()
+	if y.Sign() == 0 {
+		panic("Division by zero")
+	}
+	C.fmpq_div(&q.i[0], &x.i[0], &y.i[0])
+	return q
+}
+
+// Inv sets q to 1/x for x != 0 and returns q.
+// If x == 0, a division-by-zero run-time panic occurs.
+func (q *Fmpq) Inv(x *Fmpq) *Fmpq {
+	x.fmpqDoinit()
+	q.fmpqDoinit()
+	if x.Sign() == 0 {
+		panic("Division by zero")
+	}
+	C.fmpq_inv(&q.i[0], &x.i[0])
+	return q
+}
+
+// Neg sets q to -x and returns q.
+func (q *Fmpq) Neg(x *Fmpq) *Fmpq {
+	x.fmpqDoinit()
+	q.fmpqDoinit()
+	C.fmpq_neg(&q.i[0], &x.i[0])
+	return q
+}
+
+// Abs sets q to |x| (the absolute value of x) and returns q.
+func (q *Fmpq) Abs(x *Fmpq) *Fmpq {
+	x.fmpqDoinit()
+	q.fmpqDoinit()
+	C.fmpq_abs(&q.i[0], &x.i[0])
+	return q
+}
+
+// Pow sets q to x**e and returns q. A negative e raises the inverse of x to the power -e.
+// If x == 0 and e < 0, a division-by-zero run-time panic occurs.
+func (q *Fmpq) Pow(x *Fmpq, e int) *Fmpq {
+	x.fmpqDoinit()
+	q.fmpqDoinit()
+	if e < 0 && x.Sign() == 0 {
+		panic("Division by zero")
+	}
+	C.fmpq_pow_si(&q.i[0], &x.i[0], C.slong(e))
+	return q
+}
+
+// Floor returns the largest integer less than or equal to q.
+func (q *Fmpq) Floor() *Fmpz {
+	num, den := q.fmpqParts()
+	return num.Div(num, den)
+}
+
+// Ceil returns the smallest integer greater than or equal to q.
+func (q *Fmpq) Ceil() *Fmpz {
+	num, den := q.fmpqParts()
+	C.fmpz_cdiv_q(&num.i[0], &num.i[0], &den.i[0])
+	return num
+}
+
+// Round returns the integer nearest to q, rounding half away from zero.
+func (q *Fmpq) Round() *Fmpz {
+	num, den := q.fmpqParts()
+	neg := num.Sign() < 0
+
+	// floor((2|num| + den) / 2den)
+	num.Abs(num).Lsh(1).AddZ(den)
+	num.Div(num, den.Lsh(1))
+	if neg {
+		num.Neg(num)
+	}
+	return num
+}
+
+// maxDecimalExp bounds the exponent accepted by SetString so that a short input cannot request
+// an enormous power of ten.
+const maxDecimalExp = 1 << 24
+
+// SetString sets q to the value of s and returns q and a boolean indicating success. The string
+// may be an integer, a fraction "a/b" with a non-zero unsigned denominator, or in base 10 (or
+// base 0 without a prefix) an exact decimal such as "-3.14159" or "1.5e-3". Integer parts are
+// parsed as with Fmpz.SetString in the given base. If SetString fails q is unchanged and the
+// returned value is nil.
+func (q *Fmpq) SetString(s string, base int) (*Fmpq, bool) {
+	q.fmpqDoinit()
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		num, den := s[:i], s[i+1:]
+		if den == "" || den[0] == '+' || den[0] == '-' {
+			return nil, false
+		}
+		n, ok := new(Fmpz).SetString(num, base)
+		if !ok {
+			return nil, false
+		}
+		d, ok := new(Fmpz).SetString(den, base)
+		if !ok || d.IsZero() {
+			return nil, false
+		}
+		return q.SetFmpqFraction(n, d), true
+	}
+
+	if (base == 10 || base == 0) && strings.ContainsAny(s, ".eE") && !strings.HasPrefix(strings.ToLower(strings.TrimLeft(s, "+-")), "0x") {
+		n, d, ok := parseDecimal(s)
+		if !ok {
+			return nil, false
+		}
+		return q.SetFmpqFraction(n, d), true
+	}
+
+	n, ok := new(Fmpz).SetString(s, base)
+	if !ok {
+		return nil, false
+	}
+	return q.SetFmpqFraction(n, NewFmpz(1)), true
+}
+
+// parseDecimal parses a base 10 decimal of the form [+-]digits[.digits][(e|E)[+-]digits] into a
+// numerator and denominator.
+func parseDecimal(s string) (*Fmpz, *Fmpz, bool) {
+	mant, exp := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e > maxDecimalExp || e < -maxDecimalExp {
+			return nil, nil, false
+		}
+		mant, exp = s[:i], e
+	}
+
+	neg := false
+	if mant != "" && (mant[0] == '+' || mant[0] == '-') {
+		neg = mant[0] == '-'
+		mant = mant[1:]
+	}
+	whole, frac := mant, ""
+	if i := strings.IndexByte(mant, '.'); i >= 0 {
+		whole, frac = mant[:i], mant[i+1:]
+	}
+	digits := whole + frac
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return nil, nil, false
+	}
+
+	n, _ := new(Fmpz).SetString(digits, 10)
+	if neg {
+		n.Neg(n)
+	}
+	d := NewFmpz(1)
+	exp -= len(frac)
+	if exp > 0 {
+		n.Mul(n, new(Fmpz).ExpXI(NewFmpz(10), exp))
+	} else if exp < 0 {
+		d.ExpXI(NewFmpz(10), -exp)
+	}
+	return n, d, true
+}
+
+// FloatString returns q in decimal form with prec digits after the decimal point, rounding the
+// last digit to nearest with halves rounded away from zero. A prec <= 0 gives the rounded integer
+// without a decimal point. This matches big.Rat.FloatString.
+func (q *Fmpq) FloatString(prec int) string {
+	num, den := q.fmpqParts()
+	neg := num.Sign() < 0
+	num.Abs(num)
+	if prec > 0 {
+		num.Mul(num, new(Fmpz).ExpXI(NewFmpz(10), prec))
+	}
+
+	r := new(Fmpz)
+	num.DivMod(num, den, r)
+	if r.Lsh(1).Cmp(den) >= 0 {
+		num.AddI(1)
+	}
+
+	s := num.String()
+	if prec > 0 {
+		if len(s) <= prec {
+			s = strings.Repeat("0", prec-len(s)+1) + s
+		}
+		s = s[:len(s)-prec] + "." + s[len(s)-prec:]
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// Float64 returns the float64 value nearest to q and a bool indicating whether the result
+// represents q exactly. If the magnitude of q is too large to be represented by a float64 the
+// result is an infinity and exact is false.
+func (q *Fmpq) Float64() (float64, bool) {
+	return q.BigRat().Float64()
+}
+
+// SetFloat64 sets q to exactly f and returns q. If f is not finite q is unchanged and nil is
+// returned.
+func (q *Fmpq) SetFloat64(f float64) *Fmpq {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil
+	}
+
+	// f = frac * 2^exp with 0.5 <= |frac| < 1, so frac * 2^53 is an exact integer.
+	frac, exp := math.Frexp(f)
+	num := NewFmpz(int64(frac * (1 << 53)))
+	den := NewFmpz(1)
+	exp -= 53
+	if exp > 0 {
+		num.Lsh(exp)
+	} else if exp < 0 {
+		den.Lsh(-exp)
+	}
+	return q.SetFmpqFraction(num, den)
+}
+
+// LimitDenominator returns the closest rational to q with a denominator of at most maxDen. Ties
+// are broken in favour of the smaller denominator. If maxDen < 1 a run-time panic occurs.
+func (q *Fmpq) LimitDenominator(maxDen *Fmpz) *Fmpq {
+	closest, _, _ := q.Approximate(maxDen)
+	return closest
+}
+
+// Approximate searches the Stern-Brocot tree for the best rational approximations of q with a
+// denominator of at most maxDen. It returns the closest such rational together with the
+// neighbours of q in the Farey sequence of order maxDen: lower is the largest such rational
+// <= q and upper the smallest >= q. When the denominator of q is at most maxDen all three are
+// equal to q. The search descends by whole continued fraction terms so it takes time
+// proportional to the number of terms rather than to maxDen. If maxDen < 1 a run-time panic
+// occurs.
+func (q *Fmpq) Approximate(maxDen *Fmpz) (closest, lower, upper *Fmpq) {
+	maxDen.doinit()
+	if maxDen.Sign() <= 0 {
+		panic("Approximate: maxDen must be positive")
+	}
+	n, d := q.fmpqParts()
+	if d.Cmp(maxDen) <= 0 {
+		return new(Fmpq).Set(q), new(Fmpq).Set(q), new(Fmpq).Set(q)
+	}
+
+	// p0/q0 and p1/q1 are the last two convergents of q, bracketing it from either side.
+	p0, q0, p1, q1 := NewFmpz(0), NewFmpz(1), NewFmpz(1), NewFmpz(0)
+	a, r, t := new(Fmpz), new(Fmpz), new(Fmpz)
+	for {
+		a.DivMod(n, d, r)
+		q2 := new(Fmpz).Add(q0, t.Mul(a, q1))
+		if q2.Cmp(maxDen) > 0 {
+			break
+		}
+		p0, q0, p1, q1 = p1, q1, new(Fmpz).Add(p0, t.Mul(a, p1)), q2
+		n, d = d, new(Fmpz).Set(r)
+	}
+
+	// The other neighbour is the semiconvergent with the largest denominator allowed.
+	k := new(Fmpz).Quo(t.Sub(maxDen, q0), q1)
+	semi := NewFmpqFmpz(new(Fmpz).Add(p0, t.Mul(k, p1)), new(Fmpz).Add(q0, t.Mul(k, q1)))
+	conv := NewFmpqFmpz(p1, q1)
+
+	dSemi := new(Fmpq).Abs(new(Fmpq).Sub(semi, q))
+	dConv := new(Fmpq).Abs(new(Fmpq).Sub(conv, q))
+	closest = semi
+	if dConv.Cmp(dSemi) <= 0 {
+		closest = conv
+	}
+	lower, upper = semi, conv
+	if conv.Cmp(semi) < 0 {
+		lower, upper = conv, semi
+	}
+	return new(Fmpq).Set(closest), lower, upper
+}
+
+// ApproximateRatio returns the closest rational to num/den with a denominator of at most maxDen
+// along with its lower and upper Farey neighbours, as for Fmpq.Approximate. If den == 0, a
+// division-by-zero run-time panic occurs.
+func ApproximateRatio(num, den, maxDen *Fmpz) (closest, lower, upper *Fmpq) {
+	if den.IsZero() {
+		panic("Division by zero")
+	}
+	return NewFmpqFmpz(num, den).Approximate(maxDen)
+}
+
+// ApproximateFloat returns the closest rational to f with a denominator of at most maxDen along
+// with its lower and upper Farey neighbours, as for Fmpq.Approximate. The float is converted
+// exactly before searching. If f is not finite nil values are returned.
+func ApproximateFloat(f float64, maxDen *Fmpz) (closest, lower, upper *Fmpq) {
+	x := new(Fmpq).SetFloat64(f)
+	if x == nil {
+		return nil, nil, nil
+	}
+	return x.Approximate(maxDen)
+}
@@ -1,6 +1,10 @@
 package goflint
 
-import "testing"
+import (
+	"math"
+	"math/big"
+	"testing"
+)
 
 // TestNewFmpq tests assigning rationals and that the Stringer for the Fmpq type works
 func TestNewFmpq(t *testing.T) {
@@ -79,3 +83,595 @@ func TestDenRef(t *testing.T) {
 		t.Errorf("DenRef: got %v want %v", got, want)
 	}
 }
+
+func TestBigRat(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		r    string
+	}{
+		{
+			name: "zero",
+			r:    "0",
+		},
+		{
+			name: "negative fraction",
+			r:    "-3/2",
+		},
+		{
+			name: "multi limb fraction",
+			r:    "-863653476616376575308866344984576466644942572246900013156919/1267650600228229401496703205377",
+		},
+	} {
+		want, _ := new(big.Rat).SetString(tc.r)
+
+		q := new(Fmpq).SetBigRat(want)
+		if q.String() != tc.r {
+			t.Errorf("SetBigRat() %s want / got mismatch: %s / %v", tc.name, tc.r, q)
+		}
+
+		got := q.BigRat()
+		if got.Cmp(want) != 0 {
+			t.Errorf("BigRat() %s want / got mismatch: %v / %v", tc.name, want, got)
+		}
+	}
+}
+
+func TestReconstruct(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		a      int64
+		m      int64
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "positive fraction",
+			a:      565217396,
+			m:      1000000007,
+			want:   "17/23",
+			wantOk: true,
+		},
+		{
+			name:   "negative fraction",
+			a:      434782611,
+			m:      1000000007,
+			want:   "-17/23",
+			wantOk: true,
+		},
+		{
+			name:   "unreduced residue",
+			a:      1565217403,
+			m:      1000000007,
+			want:   "17/23",
+			wantOk: true,
+		},
+		{
+			name: "no small fraction",
+			a:    3,
+			m:    11,
+		},
+		{
+			name: "modulus too small",
+			a:    0,
+			m:    1,
+		},
+	} {
+		q := NewFmpq(5, 7)
+		got, ok := q.Reconstruct(NewFmpz(tc.a), NewFmpz(tc.m))
+		if ok != tc.wantOk {
+			t.Fatalf("Reconstruct() %s want / got ok mismatch: %v / %v", tc.name, tc.wantOk, ok)
+		}
+		if !ok {
+			if got != nil || q.String() != "5/7" {
+				t.Errorf("Reconstruct() %s failure modified q or returned a value: %v / %v", tc.name, q, got)
+			}
+			continue
+		}
+		if got.String() != tc.want {
+			t.Errorf("Reconstruct() %s want / got mismatch: %s / %v", tc.name, tc.want, got)
+		}
+
+		// Reconstruct is the inverse of ModRational.
+		back := new(Fmpz)
+		if back.ModRational(got, NewFmpz(tc.m)) != 1 || back.Int64() != tc.a%tc.m {
+			t.Errorf("ModRational() %s want / got mismatch: %d / %v", tc.name, tc.a%tc.m, back)
+		}
+	}
+}
+
+func TestReconstructBounded(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		a      int64
+		m      int64
+		n      int64
+		d      int64
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "within bounds",
+			a:      333333669,
+			m:      1000000007,
+			n:      1000,
+			d:      3,
+			want:   "1000/3",
+			wantOk: true,
+		},
+		{
+			name: "numerator bound too small",
+			a:    333333669,
+			m:    1000000007,
+			n:    999,
+			d:    3,
+		},
+		{
+			name: "bounds not unique",
+			a:    333333669,
+			m:    1000000007,
+			n:    100000,
+			d:    100000,
+		},
+		{
+			name: "zero denominator bound",
+			a:    333333669,
+			m:    1000000007,
+			n:    1000,
+			d:    0,
+		},
+	} {
+		got, ok := new(Fmpq).ReconstructBounded(NewFmpz(tc.a), NewFmpz(tc.m), NewFmpz(tc.n), NewFmpz(tc.d))
+		if ok != tc.wantOk {
+			t.Fatalf("ReconstructBounded() %s want / got ok mismatch: %v / %v", tc.name, tc.wantOk, ok)
+		}
+		if ok && got.String() != tc.want {
+			t.Errorf("ReconstructBounded() %s want / got mismatch: %s / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestContinuedFraction(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		q        *Fmpq
+		maxTerms int
+		want     []string
+	}{
+		{
+			name:     "415/93 all terms",
+			q:        NewFmpq(415, 93),
+			maxTerms: 0,
+			want:     []string{"4", "2", "6", "7"},
+		},
+		{
+			name:     "415/93 first two terms",
+			q:        NewFmpq(415, 93),
+			maxTerms: 2,
+			want:     []string{"4", "2"},
+		},
+		{
+			name:     "more terms than the expansion",
+			q:        NewFmpq(415, 93),
+			maxTerms: 100,
+			want:     []string{"4", "2", "6", "7"},
+		},
+		{
+			name:     "negative rational",
+			q:        NewFmpq(-415, 93),
+			maxTerms: 0,
+			want:     []string{"-5", "1", "1", "6", "7"},
+		},
+		{
+			name:     "integer",
+			q:        NewFmpq(7, 1),
+			maxTerms: 0,
+			want:     []string{"7"},
+		},
+	} {
+		got := tc.q.ContinuedFraction(tc.maxTerms)
+		if len(got) != len(tc.want) {
+			t.Fatalf("ContinuedFraction() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+		for i := range got {
+			if got[i].String() != tc.want[i] {
+				t.Errorf("ContinuedFraction() %s term %d want / got mismatch: %s / %v", tc.name, i, tc.want[i], got[i])
+			}
+		}
+
+		if tc.maxTerms == 0 {
+			if back := new(Fmpq).SetContinuedFraction(got); back.Cmp(tc.q) != 0 {
+				t.Errorf("SetContinuedFraction() %s want / got mismatch: %v / %v", tc.name, tc.q, back)
+			}
+		}
+	}
+}
+
+func TestSetContinuedFraction(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		terms []int64
+		want  string
+	}{
+		{
+			name:  "truncated expansion of 415/93",
+			terms: []int64{4, 2, 6},
+			want:  "58/13",
+		},
+		{
+			name: "empty",
+			want: "0",
+		},
+	} {
+		var terms []*Fmpz
+		for _, x := range tc.terms {
+			terms = append(terms, NewFmpz(x))
+		}
+		if got := NewFmpq(1, 2).SetContinuedFraction(terms); got.String() != tc.want {
+			t.Errorf("SetContinuedFraction() %s want / got mismatch: %s / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestConvergents(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		q     *Fmpq
+		want  []string
+		terms []string
+	}{
+		{
+			name:  "415/93",
+			q:     NewFmpq(415, 93),
+			want:  []string{"4", "9/2", "58/13", "415/93"},
+			terms: []string{"4", "2", "6", "7"},
+		},
+		{
+			name:  "negative rational",
+			q:     NewFmpq(-415, 93),
+			want:  []string{"-5", "-4", "-9/2", "-58/13", "-415/93"},
+			terms: []string{"-5", "1", "1", "6", "7"},
+		},
+		{
+			name:  "wiener e/N",
+			q:     NewFmpq(17993, 90581),
+			want:  []string{"0", "1/5", "29/146", "117/589", "146/735", "555/2794", "1256/6323", "5579/28086", "17993/90581"},
+			terms: []string{"0", "5", "29", "4", "1", "3", "2", "4", "3"},
+		},
+	} {
+		it := tc.q.Convergents()
+		if it.Term() != nil {
+			t.Errorf("Term() %s expected nil before Next but got %v", tc.name, it.Term())
+		}
+
+		var got, terms []string
+		for c, ok := it.Next(); ok; c, ok = it.Next() {
+			got = append(got, c.String())
+			terms = append(terms, it.Term().String())
+		}
+
+		if len(got) != len(tc.want) {
+			t.Fatalf("Convergents() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] || terms[i] != tc.terms[i] {
+				t.Errorf("Convergents() %s %d want / got mismatch: %s (%s) / %s (%s)", tc.name, i, tc.want[i], tc.terms[i], got[i], terms[i])
+			}
+		}
+	}
+}
+
+func TestNumDen(t *testing.T) {
+	q, _ := new(big.Rat).SetString("-863653476616376575308866344984576466644942572246900013156919/1267650600228229401496703205377")
+	x := new(Fmpq).SetBigRat(q)
+
+	num, den := x.Num(), x.Den()
+	if num.String() != "-863653476616376575308866344984576466644942572246900013156919" {
+		t.Errorf("Num() want / got mismatch: %v / %v", q.Num(), num)
+	}
+	if den.String() != "1267650600228229401496703205377" {
+		t.Errorf("Den() want / got mismatch: %v / %v", q.Denom(), den)
+	}
+
+	// The returned values are owned by the caller.
+	num.SetInt64(1)
+	den.SetInt64(1)
+	if x.BigRat().Cmp(q) != 0 {
+		t.Errorf("Num() / Den() modifying the result changed q to %v", x)
+	}
+}
+
+func TestFmpqArithmetic(t *testing.T) {
+	a := NewFmpq(3, 4)
+	b := NewFmpq(-5, 6)
+
+	for _, tc := range []struct {
+		name string
+		got  *Fmpq
+		want string
+	}{
+		{name: "Add", got: new(Fmpq).Add(a, b), want: "-1/12"},
+		{name: "Sub", got: new(Fmpq).Sub(a, b), want: "19/12"},
+		{name: "Mul", got: new(Fmpq).Mul(a, b), want: "-5/8"},
+		{name: "Div", got: new(Fmpq).Div(a, b), want: "-9/10"},
+		{name: "Inv", got: new(Fmpq).Inv(b), want: "-6/5"},
+		{name: "Neg", got: new(Fmpq).Neg(b), want: "5/6"},
+		{name: "Abs", got: new(Fmpq).Abs(b), want: "5/6"},
+		{name: "Pow", got: new(Fmpq).Pow(b, 3), want: "-125/216"},
+		{name: "Pow negative exponent", got: new(Fmpq).Pow(a, -2), want: "16/9"},
+		{name: "Pow zero exponent", got: new(Fmpq).Pow(b, 0), want: "1"},
+		{name: "Set", got: new(Fmpq).Set(b), want: "-5/6"},
+		{name: "Add into existing value", got: NewFmpq(1, 3).Add(NewFmpq(1, 6), NewFmpq(1, 6)), want: "1/3"},
+	} {
+		if tc.got.String() != tc.want {
+			t.Errorf("%s() want / got mismatch: %s / %v", tc.name, tc.want, tc.got)
+		}
+	}
+
+	c := NewFmpq(1, 2)
+	c.Add(c, c)
+	if c.String() != "1" {
+		t.Errorf("Add() in place want / got mismatch: 1 / %v", c)
+	}
+}
+
+func TestFmpqDivByZero(t *testing.T) {
+	for name, f := range map[string]func(){
+		"Div": func() { new(Fmpq).Div(NewFmpq(1, 2), NewFmpq(0, 1)) },
+		"Inv": func() { new(Fmpq).Inv(NewFmpq(0, 1)) },
+		"Pow": func() { new(Fmpq).Pow(NewFmpq(0, 1), -1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s() expected a division by zero panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestFmpqSign(t *testing.T) {
+	for _, tc := range []struct {
+		q    *Fmpq
+		want int
+	}{
+		{q: NewFmpq(-1, 2), want: -1},
+		{q: NewFmpq(0, 1), want: 0},
+		{q: new(Fmpq), want: 0},
+		{q: NewFmpq(1, 2), want: 1},
+	} {
+		if got := tc.q.Sign(); got != tc.want {
+			t.Errorf("Sign() %v want / got mismatch: %d / %d", tc.q, tc.want, got)
+		}
+	}
+}
+
+func TestFloorCeilRound(t *testing.T) {
+	for _, tc := range []struct {
+		q     *Fmpq
+		floor string
+		ceil  string
+		round string
+	}{
+		{q: NewFmpq(7, 2), floor: "3", ceil: "4", round: "4"},
+		{q: NewFmpq(-7, 2), floor: "-4", ceil: "-3", round: "-4"},
+		{q: NewFmpq(10, 3), floor: "3", ceil: "4", round: "3"},
+		{q: NewFmpq(-10, 3), floor: "-4", ceil: "-3", round: "-3"},
+		{q: NewFmpq(11, 3), floor: "3", ceil: "4", round: "4"},
+		{q: NewFmpq(5, 1), floor: "5", ceil: "5", round: "5"},
+		{q: NewFmpq(0, 1), floor: "0", ceil: "0", round: "0"},
+	} {
+		if got := tc.q.Floor(); got.String() != tc.floor {
+			t.Errorf("Floor() %v want / got mismatch: %s / %v", tc.q, tc.floor, got)
+		}
+		if got := tc.q.Ceil(); got.String() != tc.ceil {
+			t.Errorf("Ceil() %v want / got mismatch: %s / %v", tc.q, tc.ceil, got)
+		}
+		if got := tc.q.Round(); got.String() != tc.round {
+			t.Errorf("Round() %v want / got mismatch: %s / %v", tc.q, tc.round, got)
+		}
+	}
+}
+
+func TestFmpqSetString(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		s      string
+		base   int
+		want   string
+		wantOk bool
+	}{
+		{name: "fraction", s: "123/456", base: 10, want: "41/152", wantOk: true},
+		{name: "negative fraction", s: "-3/6", base: 10, want: "-1/2", wantOk: true},
+		{name: "integer", s: "7", base: 10, want: "7", wantOk: true},
+		{name: "decimal", s: "3.14159", base: 10, want: "314159/100000", wantOk: true},
+		{name: "negative decimal", s: "-0.5", base: 10, want: "-1/2", wantOk: true},
+		{name: "decimal without leading digit", s: ".25", base: 0, want: "1/4", wantOk: true},
+		{name: "negative exponent", s: "1.5e-3", base: 10, want: "3/2000", wantOk: true},
+		{name: "positive exponent", s: "2E3", base: 10, want: "2000", wantOk: true},
+		{name: "hexadecimal fraction", s: "ff/10", base: 16, want: "255/16", wantOk: true},
+		{name: "prefixed base", s: "0x10", base: 0, want: "16", wantOk: true},
+		{name: "upper case prefix with hex digit E", s: "0X1E", base: 0, want: "30", wantOk: true},
+		{name: "negative prefix with hex digit e", s: "-0x1e", base: 0, want: "-30", wantOk: true},
+		{name: "zero denominator", s: "1/0", base: 10},
+		{name: "signed denominator", s: "1/-2", base: 10},
+		{name: "two points", s: "1.2.3", base: 10},
+		{name: "missing digits", s: ".", base: 10},
+		{name: "bad exponent", s: "1e", base: 10},
+		{name: "huge exponent", s: "1e999999999", base: 10},
+		{name: "not a number", s: "abc", base: 10},
+		{name: "decimal in base 16", s: "1.8", base: 16},
+	} {
+		q := NewFmpq(5, 7)
+		got, ok := q.SetString(tc.s, tc.base)
+		if ok != tc.wantOk {
+			t.Errorf("SetString() %s want / got ok mismatch: %v / %v", tc.name, tc.wantOk, ok)
+			continue
+		}
+		if !ok {
+			if got != nil || q.String() != "5/7" {
+				t.Errorf("SetString() %s failure modified q or returned a value: %v / %v", tc.name, q, got)
+			}
+			continue
+		}
+		if got.String() != tc.want {
+			t.Errorf("SetString() %s want / got mismatch: %s / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestFloatString(t *testing.T) {
+	for _, tc := range []struct {
+		q    string
+		prec int
+	}{
+		{q: "1/3", prec: 5},
+		{q: "2/3", prec: 5},
+		{q: "-2/3", prec: 5},
+		{q: "1/8", prec: 2},
+		{q: "-1/8", prec: 2},
+		{q: "1/1000", prec: 2},
+		{q: "-1/1000", prec: 2},
+		{q: "5/2", prec: 0},
+		{q: "-5/2", prec: 0},
+		{q: "22/7", prec: 30},
+		{q: "123456789/1000", prec: 1},
+	} {
+		r, _ := new(big.Rat).SetString(tc.q)
+		want := r.FloatString(tc.prec)
+
+		q, _ := new(Fmpq).SetString(tc.q, 10)
+		if got := q.FloatString(tc.prec); got != want {
+			t.Errorf("FloatString() %s prec %d want / got mismatch: %s / %s", tc.q, tc.prec, want, got)
+		}
+
+		// The decimal output can be read back.
+		back, ok := new(Fmpq).SetString(want, 10)
+		if !ok || back.BigRat().FloatString(tc.prec) != want {
+			t.Errorf("SetString() of FloatString() %s failed to round trip: %v", want, back)
+		}
+	}
+}
+
+func TestFmpqFloat64(t *testing.T) {
+	for _, tc := range []struct {
+		q     string
+		want  float64
+		exact bool
+	}{
+		{q: "1/2", want: 0.5, exact: true},
+		{q: "-3/4", want: -0.75, exact: true},
+		{q: "1/3", want: 1.0 / 3, exact: false},
+		{q: "0", want: 0, exact: true},
+		{q: "9007199254740993", want: 9007199254740992, exact: false},
+	} {
+		q, _ := new(Fmpq).SetString(tc.q, 10)
+		got, exact := q.Float64()
+		if got != tc.want || exact != tc.exact {
+			t.Errorf("Float64() %s want / got mismatch: %v (%v) / %v (%v)", tc.q, tc.want, tc.exact, got, exact)
+		}
+	}
+}
+
+func TestFmpqSetFloat64(t *testing.T) {
+	for _, f := range []float64{0, 1, -1, 0.1, -2.5, 1e300, 5e-324, 123456.789} {
+		want := new(big.Rat).SetFloat64(f)
+		got := new(Fmpq).SetFloat64(f)
+		if got.BigRat().Cmp(want) != 0 {
+			t.Errorf("SetFloat64() %v want / got mismatch: %v / %v", f, want, got)
+		}
+		if back, exact := got.Float64(); back != f || !exact {
+			t.Errorf("Float64() of SetFloat64() %v want / got mismatch: %v (true) / %v (%v)", f, f, back, exact)
+		}
+	}
+
+	q := NewFmpq(5, 7)
+	if got := q.SetFloat64(math.Inf(1)); got != nil || q.String() != "5/7" {
+		t.Errorf("SetFloat64() expected nil and unchanged q for infinity but got %v / %v", got, q)
+	}
+}
+
+func TestApproximate(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		q       *Fmpq
+		maxDen  int64
+		closest string
+		lower   string
+		upper   string
+	}{
+		{
+			name:    "pi with denominators up to 100",
+			q:       new(Fmpq).SetFloat64(math.Pi),
+			maxDen:  100,
+			closest: "311/99",
+			lower:   "311/99",
+			upper:   "22/7",
+		},
+		{
+			name:    "pi with denominators up to 1000",
+			q:       new(Fmpq).SetFloat64(math.Pi),
+			maxDen:  1000,
+			closest: "355/113",
+			lower:   "2818/897",
+			upper:   "355/113",
+		},
+		{
+			name:    "negative rational",
+			q:       NewFmpq(-415, 93),
+			maxDen:  10,
+			closest: "-40/9",
+			lower:   "-9/2",
+			upper:   "-40/9",
+		},
+		{
+			name:    "denominator already small enough",
+			q:       NewFmpq(1, 3),
+			maxDen:  5,
+			closest: "1/3",
+			lower:   "1/3",
+			upper:   "1/3",
+		},
+		{
+			name:    "neighbour at zero",
+			q:       NewFmpq(3, 7),
+			maxDen:  2,
+			closest: "1/2",
+			lower:   "0",
+			upper:   "1/2",
+		},
+	} {
+		closest, lower, upper := tc.q.Approximate(NewFmpz(tc.maxDen))
+		if closest.String() != tc.closest || lower.String() != tc.lower || upper.String() != tc.upper {
+			t.Errorf("Approximate() %s want / got mismatch: %s [%s, %s] / %v [%v, %v]",
+				tc.name, tc.closest, tc.lower, tc.upper, closest, lower, upper)
+		}
+
+		if got := tc.q.LimitDenominator(NewFmpz(tc.maxDen)); got.String() != tc.closest {
+			t.Errorf("LimitDenominator() %s want / got mismatch: %s / %v", tc.name, tc.closest, got)
+		}
+	}
+}
+
+func TestApproximateRatio(t *testing.T) {
+	// The ratio of the factors of a small RSA modulus.
+	p, _ := new(Fmpz).SetString("30000000000011", 10)
+	q, _ := new(Fmpz).SetString("1000000000039", 10)
+
+	closest, lower, upper := ApproximateRatio(p, q, NewFmpz(1000))
+	if closest.String() != "30" || lower.String() != "29999/1000" || upper.String() != "30" {
+		t.Errorf("ApproximateRatio() want / got mismatch: 30 [29999/1000, 30] / %v [%v, %v]", closest, lower, upper)
+	}
+}
+
+func TestApproximateFloat(t *testing.T) {
+	closest, lower, upper := ApproximateFloat(0.3333, NewFmpz(10))
+	if closest.String() != "1/3" || lower.String() != "3/10" || upper.String() != "1/3" {
+		t.Errorf("ApproximateFloat() want / got mismatch: 1/3 [3/10, 1/3] / %v [%v, %v]", closest, lower, upper)
+	}
+
+	if closest, _, _ := ApproximateFloat(math.NaN(), NewFmpz(10)); closest != nil {
+		t.Errorf("ApproximateFloat() expected nil for NaN but got %v", closest)
+	}
+}
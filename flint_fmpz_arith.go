@@ -0,0 +1,337 @@
+package goflint
+
+/*
+#include <flint/flint.h>
+#include <flint/fmpz.h>
+#include <flint/fmpz_factor.h>
+#include <flint/arith.h>
+
+// Helper functions for FLINT 2/3 compatibility. FLINT 3 moved the power k in front of n in the
+// divisor sigma functions.
+static void compat_fmpz_divisor_sigma(fmpz_t res, const fmpz_t n, ulong k) {
+    #if __FLINT_RELEASE >= 30000
+        fmpz_divisor_sigma(res, k, n);
+    #else
+        fmpz_divisor_sigma(res, n, k);
+    #endif
+}
+
+static void compat_fmpz_factor_divisor_sigma(fmpz_t res, const fmpz_factor_t fac, ulong k) {
+    #if __FLINT_RELEASE >= 30000
+        fmpz_factor_divisor_sigma(res, k, fac);
+    #else
+        fmpz_factor_divisor_sigma(res, fac, k);
+    #endif
+}
+*/
+import "C"
+
+import "fmt"
+
+// Arithmetic functions. Each is defined on the positive integers: an Fmpz argument n is taken as
+// |n|, and the value for 0 is 0. The FmpzFactor forms skip factoring when the factorization is
+// already known and ignore its sign.
+
+// EulerPhi sets z to Euler's totient function of n, the number of integers in [1, n] coprime to
+// n, and returns z.
+func (z *Fmpz) EulerPhi(n *Fmpz) *Fmpz {
+	z.doinit()
+	n.doinit()
+	if n.IsZero() {
+		return z.SetInt64(0)
+	}
+	C.fmpz_euler_phi(&z.i[0], &new(Fmpz).Abs(n).i[0])
+	return z
+}
+
+// EulerPhi returns Euler's totient function of the factored integer.
+func (f *FmpzFactor) EulerPhi() *Fmpz {
+	z := new(Fmpz)
+	z.doinit()
+	if f.Sign() == 0 {
+		return z
+	}
+	C.fmpz_factor_euler_phi(&z.i[0], &f.i[0])
+	return z
+}
+
+// CarmichaelLambda sets z to the Carmichael function of n, the exponent of the multiplicative
+// group modulo n, and returns z. Every unit a modulo n has a^z = 1 (mod n), and z divides
+// EulerPhi(n). This factors n.
+func (z *Fmpz) CarmichaelLambda(n *Fmpz) *Fmpz {
+	return z.Set(new(Fmpz).Abs(n).Factor().CarmichaelLambda())
+}
+
+// CarmichaelLambda returns the Carmichael function of the factored integer, the least common
+// multiple of lambda(p^e) over its prime powers, where lambda(p^e) = p^(e-1)*(p-1) for odd p and
+// lambda(2^e) is 1, 2 and 2^(e-2) for e = 1, e = 2 and e >= 3.
+func (f *FmpzFactor) CarmichaelLambda() *Fmpz {
+	if f.Sign() == 0 {
+		return new(Fmpz)
+	}
+	l := NewFmpz(1)
+	t := new(Fmpz)
+	for i, p := range f.Primes() {
+		e := f.GetExp(i)
+		switch {
+		case p.Cmp(NewFmpz(2)) != 0:
+			t.ExpXI(p, e-1).MulZ(new(Fmpz).Sub(p, NewFmpz(1)))
+		case e <= 2:
+			t.SetInt64(int64(e))
+		default:
+			t.SetInt64(0).SetBit(e - 2)
+		}
+		l.Lcm(l, t)
+	}
+	return l
+}
+
+// Moebius returns the Moebius function of z: 0 if z has a square factor, otherwise 1 or -1 as z
+// has an even or odd number of prime factors.
+func (z *Fmpz) Moebius() int {
+	z.doinit()
+	if z.IsZero() {
+		return 0
+	}
+	return int(C.fmpz_moebius_mu(&new(Fmpz).Abs(z).i[0]))
+}
+
+// Moebius returns the Moebius function of the factored integer.
+func (f *FmpzFactor) Moebius() int {
+	if f.Sign() == 0 {
+		return 0
+	}
+	return int(C.fmpz_factor_moebius_mu(&f.i[0]))
+}
+
+// DivisorSigma sets z to the sum of the kth powers of the positive divisors of n and returns z.
+// With k = 0 this counts the divisors and with k = 1 it sums them. If k < 0 a run-time panic
+// occurs.
+func (z *Fmpz) DivisorSigma(n *Fmpz, k int) *Fmpz {
+	if k < 0 {
+		panic("DivisorSigma: negative power")
+	}
+	z.doinit()
+	n.doinit()
+	if n.IsZero() {
+		return z.SetInt64(0)
+	}
+	C.compat_fmpz_divisor_sigma(&z.i[0], &new(Fmpz).Abs(n).i[0], C.ulong(k))
+	return z
+}
+
+// DivisorSigma returns the sum of the kth powers of the positive divisors of the factored
+// integer. If k < 0 a run-time panic occurs.
+func (f *FmpzFactor) DivisorSigma(k int) *Fmpz {
+	if k < 0 {
+		panic("DivisorSigma: negative power")
+	}
+	z := new(Fmpz)
+	z.doinit()
+	if f.Sign() == 0 {
+		return z
+	}
+	C.compat_fmpz_factor_divisor_sigma(&z.i[0], &f.i[0], C.ulong(k))
+	return z
+}
+
+// NumDivisors sets z to the number of positive divisors of n and returns z.
+func (z *Fmpz) NumDivisors(n *Fmpz) *Fmpz {
+	return z.DivisorSigma(n, 0)
+}
+
+// NumDivisors returns the number of positive divisors of the factored integer, the product of
+// e+1 over its prime powers p^e.
+func (f *FmpzFactor) NumDivisors() *Fmpz {
+	if f.Sign() == 0 {
+		return new(Fmpz)
+	}
+	z := NewFmpz(1)
+	for _, e := range f.Exponents() {
+		z.MulI(e + 1)
+	}
+	return z
+}
+
+// Divisors iterates over the positive divisors of an integer. Create one with Fmpz.Divisors or
+// FmpzFactor.Divisors.
+type Divisors struct {
+	primes []*Fmpz
+	exps   []int
+	powers []*Fmpz
+	idx    []int
+	cur    *Fmpz
+	done   bool
+}
+
+// Divisors returns an iterator over the positive divisors of |z|. This factors z.
+func (z *Fmpz) Divisors() *Divisors {
+	return new(Fmpz).Abs(z).Factor().Divisors()
+}
+
+// Divisors returns an iterator over the positive divisors of the factored integer. There are
+// none for 0.
+func (f *FmpzFactor) Divisors() *Divisors {
+	d := &Divisors{
+		primes: f.Primes(),
+		exps:   f.Exponents(),
+		cur:    NewFmpz(1),
+		done:   f.Sign() == 0,
+	}
+	d.idx = make([]int, len(d.primes))
+	for i, p := range d.primes {
+		d.powers = append(d.powers, new(Fmpz).ExpXI(p, d.exps[i]))
+	}
+	return d
+}
+
+// Next returns the next divisor and true, or nil and false once every divisor has been
+// returned. Each divisor is returned exactly once, starting with 1 but otherwise in no particular
+// order; sort them if order matters.
+func (d *Divisors) Next() (*Fmpz, bool) {
+	if d.done {
+		return nil, false
+	}
+	out := new(Fmpz).Set(d.cur)
+
+	// Step the exponent of each prime like an odometer, keeping cur equal to the product of
+	// primes[i]^idx[i].
+	i := 0
+	for ; i < len(d.idx); i++ {
+		if d.idx[i] < d.exps[i] {
+			d.idx[i]++
+			d.cur.MulZ(d.primes[i])
+			break
+		}
+		d.idx[i] = 0
+		d.cur.Quo(d.cur, d.powers[i])
+	}
+	d.done = i == len(d.idx)
+	return out, true
+}
+
+// MultiplicativeOrder sets z to the order of a modulo n, the least k > 0 with a^k = 1 (mod n),
+// and returns z. The optional phiFac is the factorization of phi(n), or of any multiple of the
+// order of a such as the Carmichael function of n, and saves factoring n and lambda(n). An error
+// is returned if n < 1, if a is not a unit modulo n, or if a^N != 1 (mod n) for the integer N
+// factored by phiFac. Passing more than one factorization causes a run-time panic.
+func (z *Fmpz) MultiplicativeOrder(a, n *Fmpz, phiFac ...*FmpzFactor) (*Fmpz, error) {
+	if len(phiFac) > 1 {
+		panic("MultiplicativeOrder: at most one factorization is allowed")
+	}
+	if n.Sign() <= 0 {
+		return nil, fmt.Errorf("MultiplicativeOrder: modulus %v is not positive", n)
+	}
+	one := NewFmpz(1)
+	ar := new(Fmpz).Mod(a, n)
+	if !new(Fmpz).GCD(ar, n).Equals(one) {
+		return nil, fmt.Errorf("MultiplicativeOrder: %v is not a unit modulo %v", a, n)
+	}
+	if n.Equals(one) {
+		return z.SetInt64(1), nil
+	}
+
+	var fac *FmpzFactor
+	if len(phiFac) == 1 {
+		fac = phiFac[0]
+	} else {
+		fac = n.Factor().CarmichaelLambda().Factor()
+	}
+	bigN, err := factorValue("MultiplicativeOrder", fac)
+	if err != nil {
+		return nil, err
+	}
+	if !new(Fmpz).Exp(ar, bigN, n).Equals(one) {
+		return nil, fmt.Errorf("MultiplicativeOrder: %v^%v is not 1 modulo %v", a, bigN, n)
+	}
+	return z.Set(multiplicativeOrder(ar, n, bigN, fac)), nil
+}
+
+// factorValue returns the integer factored by fac, or an error if it is not positive.
+func factorValue(fn string, fac *FmpzFactor) (*Fmpz, error) {
+	moduli, err := factorPrimePowers(fn, fac)
+	if err != nil {
+		return nil, err
+	}
+	v := NewFmpz(1)
+	for _, m := range moduli {
+		v.MulZ(m)
+	}
+	return v, nil
+}
+
+// PrimitiveRoot sets z to the least primitive root modulo n, a generator of the multiplicative
+// group modulo n, and returns z. A primitive root exists only when n is 1, 2, 4, an odd prime
+// power p^k or twice one, and an error is returned for any other n; this is checked without
+// factoring n. The optional phiFac is the factorization of phi(n) = p^(k-1)*(p-1), which
+// otherwise requires factoring p-1. An error is also returned if phiFac does not factor phi(n).
+// Passing more than one factorization causes a run-time panic.
+func (z *Fmpz) PrimitiveRoot(n *Fmpz, phiFac ...*FmpzFactor) (*Fmpz, error) {
+	if len(phiFac) > 1 {
+		panic("PrimitiveRoot: at most one factorization is allowed")
+	}
+	if n.Sign() <= 0 {
+		return nil, fmt.Errorf("PrimitiveRoot: modulus %v is not positive", n)
+	}
+	switch {
+	case n.Equals(NewFmpz(1)):
+		return z.SetInt64(0), nil
+	case n.Equals(NewFmpz(2)):
+		return z.SetInt64(1), nil
+	case n.Equals(NewFmpz(4)):
+		return z.SetInt64(3), nil
+	}
+
+	// Strip a single factor of 2, after which n must be a power of an odd prime p.
+	m := new(Fmpz).Set(n)
+	if m.TstBit(0) == 0 {
+		m.Rsh(1)
+	}
+	p, k := m.IsPerfectPower()
+	if p == nil {
+		p, k = m, 1
+	}
+	if p.TstBit(0) == 0 || p.Cmp(NewFmpz(3)) < 0 || p.IsProbabPrime() == 0 {
+		return nil, fmt.Errorf("PrimitiveRoot: %v has no primitive root as it is not 1, 2, 4, p^k or 2p^k for an odd prime p", n)
+	}
+
+	pm1 := new(Fmpz).Sub(p, NewFmpz(1))
+	phi := new(Fmpz).ExpXI(p, k-1).MulZ(pm1)
+	var fac *FmpzFactor
+	if len(phiFac) == 1 {
+		fac = phiFac[0]
+		v, err := factorValue("PrimitiveRoot", fac)
+		if err != nil {
+			return nil, err
+		}
+		if !v.Equals(phi) {
+			return nil, fmt.Errorf("PrimitiveRoot: factorization %v is not of phi(%v) = %v", fac, n, phi)
+		}
+	} else {
+		fac = pm1.Factor()
+		if k > 1 {
+			fac.Append(p, k-1)
+		}
+	}
+
+	// g is a primitive root exactly when g^(phi/q) != 1 for every prime q dividing phi. Modulo 2p^k
+	// the root must also be odd.
+	one := NewFmpz(1)
+	primes := fac.Primes()
+	t := new(Fmpz)
+	for g := NewFmpz(2); ; g.AddI(1) {
+		if !t.GCD(g, n).Equals(one) {
+			continue
+		}
+		primitive := true
+		for _, q := range primes {
+			if t.Exp(g, new(Fmpz).Quo(phi, q), n).Equals(one) {
+				primitive = false
+				break
+			}
+		}
+		if primitive {
+			return z.Set(g), nil
+		}
+	}
+}
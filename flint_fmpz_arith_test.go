@@ -0,0 +1,382 @@
+package goflint
+
+import (
+	"testing"
+)
+
+func TestArithmeticFunctions(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		n       string
+		phi     string
+		lambda  string
+		mu      int
+		sigma1  string
+		sigma2  string
+		divisor string
+	}{
+		{
+			name:    "one",
+			n:       "1",
+			phi:     "1",
+			lambda:  "1",
+			mu:      1,
+			sigma1:  "1",
+			sigma2:  "1",
+			divisor: "1",
+		},
+		{
+			name:    "square factors",
+			n:       "36",
+			phi:     "12",
+			lambda:  "6",
+			mu:      0,
+			sigma1:  "91",
+			sigma2:  "1911",
+			divisor: "9",
+		},
+		{
+			name:    "Carmichael number",
+			n:       "561",
+			phi:     "320",
+			lambda:  "80",
+			mu:      -1,
+			sigma1:  "864",
+			sigma2:  "353800",
+			divisor: "8",
+		},
+		{
+			name:    "power of two above four",
+			n:       "1960",
+			phi:     "672",
+			lambda:  "84",
+			mu:      0,
+			sigma1:  "5130",
+			sigma2:  "5416710",
+			divisor: "24",
+		},
+		{
+			name:    "RSA modulus",
+			n:       "30000000001181000000000429",
+			phi:     "30000000001150000000000380",
+			lambda:  "15000000000575000000000190",
+			mu:      1,
+			sigma1:  "30000000001212000000000480",
+			sigma2:  "900000000070860000001421402000001014036000000185684",
+			divisor: "4",
+		},
+		{
+			name:    "negative value",
+			n:       "-561",
+			phi:     "320",
+			lambda:  "80",
+			mu:      -1,
+			sigma1:  "864",
+			sigma2:  "353800",
+			divisor: "8",
+		},
+		{
+			name:    "zero",
+			n:       "0",
+			phi:     "0",
+			lambda:  "0",
+			mu:      0,
+			sigma1:  "0",
+			sigma2:  "0",
+			divisor: "0",
+		},
+	} {
+		n, _ := new(Fmpz).SetString(tc.n, 10)
+		fac := n.Factor()
+
+		for _, c := range []struct {
+			fn   string
+			want string
+			got  *Fmpz
+		}{
+			{"EulerPhi", tc.phi, new(Fmpz).EulerPhi(n)},
+			{"FmpzFactor.EulerPhi", tc.phi, fac.EulerPhi()},
+			{"CarmichaelLambda", tc.lambda, new(Fmpz).CarmichaelLambda(n)},
+			{"FmpzFactor.CarmichaelLambda", tc.lambda, fac.CarmichaelLambda()},
+			{"DivisorSigma(1)", tc.sigma1, new(Fmpz).DivisorSigma(n, 1)},
+			{"FmpzFactor.DivisorSigma(1)", tc.sigma1, fac.DivisorSigma(1)},
+			{"DivisorSigma(2)", tc.sigma2, new(Fmpz).DivisorSigma(n, 2)},
+			{"FmpzFactor.DivisorSigma(2)", tc.sigma2, fac.DivisorSigma(2)},
+			{"NumDivisors", tc.divisor, new(Fmpz).NumDivisors(n)},
+			{"FmpzFactor.NumDivisors", tc.divisor, fac.NumDivisors()},
+		} {
+			if c.got.String() != c.want {
+				t.Errorf("%s() %s want / got mismatch: %s / %v", c.fn, tc.name, c.want, c.got)
+			}
+		}
+
+		if got := n.Moebius(); got != tc.mu {
+			t.Errorf("Moebius() %s want / got mismatch: %d / %d", tc.name, tc.mu, got)
+		}
+		if got := fac.Moebius(); got != tc.mu {
+			t.Errorf("FmpzFactor.Moebius() %s want / got mismatch: %d / %d", tc.name, tc.mu, got)
+		}
+	}
+}
+
+func TestDivisors(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		n         int64
+		wantCount int
+		wantSum   int64
+	}{
+		{
+			name:      "one",
+			n:         1,
+			wantCount: 1,
+			wantSum:   1,
+		},
+		{
+			name:      "prime",
+			n:         97,
+			wantCount: 2,
+			wantSum:   98,
+		},
+		{
+			name:      "highly composite",
+			n:         360,
+			wantCount: 24,
+			wantSum:   1170,
+		},
+		{
+			name:      "negative value",
+			n:         -360,
+			wantCount: 24,
+			wantSum:   1170,
+		},
+		{
+			name: "zero",
+			n:    0,
+		},
+	} {
+		n := NewFmpz(tc.n)
+		seen := make(map[string]bool)
+		sum := new(Fmpz)
+		d := n.Divisors()
+		for x, ok := d.Next(); ok; x, ok = d.Next() {
+			if seen[x.String()] {
+				t.Errorf("Divisors() %s returned %v twice", tc.name, x)
+			}
+			seen[x.String()] = true
+			if !new(Fmpz).Mod(n, x).IsZero() {
+				t.Errorf("Divisors() %s returned %v which does not divide %v", tc.name, x, n)
+			}
+			sum.AddZ(x)
+		}
+
+		if len(seen) != tc.wantCount || sum.Int64() != tc.wantSum {
+			t.Errorf("Divisors() %s count and sum want / got mismatch: %d, %d / %d, %v", tc.name, tc.wantCount, tc.wantSum, len(seen), sum)
+		}
+		if _, ok := d.Next(); ok {
+			t.Errorf("Divisors() %s returned a value after finishing", tc.name)
+		}
+	}
+
+	first, _ := NewFmpz(360).Divisors().Next()
+	if first.String() != "1" {
+		t.Errorf("Divisors() first divisor want / got mismatch: 1 / %v", first)
+	}
+}
+
+func TestMultiplicativeOrder(t *testing.T) {
+	p := NewFmpz(1000000007)
+	for _, tc := range []struct {
+		name    string
+		a       *Fmpz
+		n       *Fmpz
+		phiFac  []*FmpzFactor
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Carmichael number",
+			a:    NewFmpz(10),
+			n:    NewFmpz(561),
+			want: "16",
+		},
+		{
+			name: "product of two primes",
+			a:    NewFmpz(3),
+			n:    NewFmpz(8633),
+			want: "528",
+		},
+		{
+			name: "prime",
+			a:    NewFmpz(2),
+			n:    p,
+			want: "500000003",
+		},
+		{
+			name:   "prime with factorization of phi",
+			a:      NewFmpz(2),
+			n:      p,
+			phiFac: []*FmpzFactor{NewFmpzFactor().Append(NewFmpz(2), 1).Append(NewFmpz(500000003), 1)},
+			want:   "500000003",
+		},
+		{
+			name: "modulus one",
+			a:    NewFmpz(5),
+			n:    NewFmpz(1),
+			want: "1",
+		},
+		{
+			name:    "not a unit",
+			a:       NewFmpz(7),
+			n:       NewFmpz(77),
+			wantErr: true,
+		},
+		{
+			name:    "factorization not a multiple of the order",
+			a:       NewFmpz(2),
+			n:       NewFmpz(77),
+			phiFac:  []*FmpzFactor{NewFmpz(7).Factor()},
+			wantErr: true,
+		},
+		{
+			name:    "zero modulus",
+			a:       NewFmpz(2),
+			n:       NewFmpz(0),
+			wantErr: true,
+		},
+	} {
+		got, err := new(Fmpz).MultiplicativeOrder(tc.a, tc.n, tc.phiFac...)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("MultiplicativeOrder() %s expected error but got %v", tc.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("MultiplicativeOrder() %s got error when not expected: %v", tc.name, err)
+		}
+		if got.String() != tc.want {
+			t.Errorf("MultiplicativeOrder() %s want / got mismatch: %s / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestPrimitiveRoot(t *testing.T) {
+	p := NewFmpz(1000000007)
+	sp, _ := new(Fmpz).SetString(smoothPrime, 10)
+	rsa, _ := new(Fmpz).SetString("30000000001181000000000429", 10)
+	for _, tc := range []struct {
+		name    string
+		n       *Fmpz
+		phiFac  []*FmpzFactor
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "one",
+			n:    NewFmpz(1),
+			want: "0",
+		},
+		{
+			name: "two",
+			n:    NewFmpz(2),
+			want: "1",
+		},
+		{
+			name: "four",
+			n:    NewFmpz(4),
+			want: "3",
+		},
+		{
+			name: "three",
+			n:    NewFmpz(3),
+			want: "2",
+		},
+		{
+			name: "odd prime square",
+			n:    NewFmpz(9),
+			want: "2",
+		},
+		{
+			name: "twice a prime square",
+			n:    NewFmpz(18),
+			want: "5",
+		},
+		{
+			name: "twice a prime power",
+			n:    NewFmpz(50),
+			want: "3",
+		},
+		{
+			name: "twice a larger prime square",
+			n:    NewFmpz(1058),
+			want: "5",
+		},
+		{
+			name: "square of 11",
+			n:    NewFmpz(121),
+			want: "2",
+		},
+		{
+			name: "large prime",
+			n:    p,
+			want: "5",
+		},
+		{
+			name: "twice a large prime squared",
+			n:    new(Fmpz).Mul(p, p).MulI(2),
+			want: "5",
+		},
+		{
+			name:   "smooth prime with factorization of phi",
+			n:      sp,
+			phiFac: []*FmpzFactor{new(Fmpz).Sub(sp, NewFmpz(1)).Factor()},
+			want:   "103",
+		},
+		{
+			name:    "eight",
+			n:       NewFmpz(8),
+			wantErr: true,
+		},
+		{
+			name:    "twelve",
+			n:       NewFmpz(12),
+			wantErr: true,
+		},
+		{
+			name:    "two odd primes",
+			n:       NewFmpz(77),
+			wantErr: true,
+		},
+		{
+			name:    "RSA modulus",
+			n:       rsa,
+			wantErr: true,
+		},
+		{
+			name:    "zero",
+			n:       NewFmpz(0),
+			wantErr: true,
+		},
+		{
+			name:    "wrong factorization of phi",
+			n:       p,
+			phiFac:  []*FmpzFactor{NewFmpz(1000000005).Factor()},
+			wantErr: true,
+		},
+	} {
+		got, err := new(Fmpz).PrimitiveRoot(tc.n, tc.phiFac...)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("PrimitiveRoot() %s expected error but got %v", tc.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("PrimitiveRoot() %s got error when not expected: %v", tc.name, err)
+		}
+		if got.String() != tc.want {
+			t.Errorf("PrimitiveRoot() %s want / got mismatch: %s / %v", tc.name, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package goflint
+
+/*
+#include <flint/flint.h>
+#include <flint/fmpz.h>
+#include <flint/fmpz_vec.h>
+
+fmpz *_fmpz_vec_entry(fmpz *v, slong i);
+
+// Helper functions for FLINT 2/3 compatibility. FLINT 3 renamed fmpz_multi_crt to
+// fmpz_multi_CRT and added a sign argument, releases before 2.6 have no multi modulus CRT at all
+// and report -1 so that the caller falls back to its own implementation. Results are always
+// reduced into [0, M) by the caller so the sign convention does not matter.
+#if __FLINT_RELEASE >= 30000
+typedef fmpz_multi_CRT_struct compat_multi_crt_struct;
+#elif __FLINT_RELEASE >= 20600
+typedef fmpz_multi_crt_struct compat_multi_crt_struct;
+#else
+typedef struct { int unused; } compat_multi_crt_struct;
+#endif
+typedef compat_multi_crt_struct compat_multi_crt_t[1];
+
+static void compat_multi_crt_init(compat_multi_crt_t P) {
+    #if __FLINT_RELEASE >= 30000
+        fmpz_multi_CRT_init(P);
+    #elif __FLINT_RELEASE >= 20600
+        fmpz_multi_crt_init(P);
+    #endif
+}
+
+static void compat_multi_crt_clear(compat_multi_crt_t P) {
+    #if __FLINT_RELEASE >= 30000
+        fmpz_multi_CRT_clear(P);
+    #elif __FLINT_RELEASE >= 20600
+        fmpz_multi_crt_clear(P);
+    #endif
+}
+
+static int compat_multi_crt_precompute(compat_multi_crt_t P, const fmpz *moduli, slong len) {
+    #if __FLINT_RELEASE >= 30000
+        return fmpz_multi_CRT_precompute(P, moduli, len);
+    #elif __FLINT_RELEASE >= 20600
+        return fmpz_multi_crt_precompute(P, moduli, len);
+    #else
+        return -1;
+    #endif
+}
+
+static void compat_multi_crt_precomp(fmpz_t out, const compat_multi_crt_t P, const fmpz *inputs) {
+    #if __FLINT_RELEASE >= 30000
+        fmpz_multi_CRT_precomp(out, P, inputs, 0);
+    #elif __FLINT_RELEASE >= 20600
+        fmpz_multi_crt_precomp(out, P, inputs);
+    #endif
+}
+
+static int compat_multi_crt(fmpz_t out, const fmpz *moduli, const fmpz *values, slong len) {
+    #if __FLINT_RELEASE >= 30000
+        return fmpz_multi_CRT(out, moduli, values, len, 0);
+    #elif __FLINT_RELEASE >= 20600
+        return fmpz_multi_crt(out, moduli, values, len);
+    #else
+        return -1;
+    #endif
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// CRTContext holds a precomputed Chinese remaindering for a fixed list of pairwise coprime
+// moduli so that many sets of residues can be combined cheaply.
+type CRTContext struct {
+	i      C.compat_multi_crt_t
+	moduli []*Fmpz
+	m      *Fmpz
+	native bool
+	init   bool
+}
+
+// crtContextFinalize releases the memory allocated to the CRTContext.
+func crtContextFinalize(c *CRTContext) {
+	if c.init {
+		runtime.SetFinalizer(c, nil)
+		C.compat_multi_crt_clear(&c.i[0])
+		c.init = false
+	}
+}
+
+// crtContextDoinit initializes a CRTContext type.
+func (c *CRTContext) crtContextDoinit() {
+	if c.init {
+		return
+	}
+	c.init = true
+	C.compat_multi_crt_init(&c.i[0])
+	runtime.SetFinalizer(c, crtContextFinalize)
+}
+
+// fmpzVec copies xs into a newly allocated FLINT vector which must be released with
+// C._fmpz_vec_clear.
+func fmpzVec(xs []*Fmpz) *C.fmpz {
+	v := C._fmpz_vec_init(C.slong(len(xs)))
+	for i, x := range xs {
+		x.doinit()
+		C.fmpz_set(C._fmpz_vec_entry(v, C.slong(i)), &x.i[0])
+	}
+	return v
+}
+
+// checkModuli returns an error if moduli is empty or holds a modulus less than 1.
+func checkModuli(fn string, moduli []*Fmpz) error {
+	if len(moduli) == 0 {
+		return fmt.Errorf("%s: no moduli", fn)
+	}
+	for _, m := range moduli {
+		if m.Sign() <= 0 {
+			return fmt.Errorf("%s: modulus %v is not positive", fn, m)
+		}
+	}
+	return nil
+}
+
+// NewCRTContext precomputes the Chinese remaindering for moduli and returns the context. The
+// moduli must be positive and pairwise coprime, otherwise an error is returned; use MultiCRT for
+// moduli with common factors.
+func NewCRTContext(moduli []*Fmpz) (*CRTContext, error) {
+	if err := checkModuli("NewCRTContext", moduli); err != nil {
+		return nil, err
+	}
+
+	c := new(CRTContext)
+	c.m = NewFmpz(1)
+	g := new(Fmpz)
+	for _, m := range moduli {
+		if g.GCD(c.m, m).Cmp(NewFmpz(1)) != 0 {
+			return nil, fmt.Errorf("NewCRTContext: modulus %v shares the factor %v with an earlier modulus", m, g)
+		}
+		c.m.Mul(c.m, m)
+		c.moduli = append(c.moduli, new(Fmpz).Set(m))
+	}
+
+	// FLINT rejects a modulus of 1 so those are combined by the fallback.
+	c.crtContextDoinit()
+	v := fmpzVec(c.moduli)
+	defer C._fmpz_vec_clear(v, C.slong(len(c.moduli)))
+	c.native = C.compat_multi_crt_precompute(&c.i[0], v, C.slong(len(c.moduli))) == 1
+	return c, nil
+}
+
+// Modulus returns a copy of the product M of the moduli of the context.
+func (c *CRTContext) Modulus() *Fmpz {
+	return new(Fmpz).Set(c.m)
+}
+
+// CRT sets z to the unique value 0 <= z < M congruent to residues[i] modulo the ith modulus of
+// the context, and returns z. An error is returned if the number of residues does not match the
+// number of moduli.
+func (c *CRTContext) CRT(z *Fmpz, residues []*Fmpz) (*Fmpz, error) {
+	if len(residues) != len(c.moduli) {
+		return nil, fmt.Errorf("CRT: %d residues for %d moduli", len(residues), len(c.moduli))
+	}
+	z.doinit()
+	if !c.native {
+		return crtFold(z, residues, c.moduli)
+	}
+
+	v := fmpzVec(residues)
+	defer C._fmpz_vec_clear(v, C.slong(len(residues)))
+	C.compat_multi_crt_precomp(&z.i[0], &c.i[0], v)
+	return z.Mod(z, c.m), nil
+}
+
+// MultiCRT sets z to the unique value 0 <= z < lcm(moduli) congruent to residues[i] modulo
+// moduli[i] for every i, and returns z. The moduli must be positive but need not be coprime:
+// congruences with common factors are merged when they are consistent, and an error is returned
+// when they are not, or when the slices are empty or of different lengths.
+func (z *Fmpz) MultiCRT(residues, moduli []*Fmpz) (*Fmpz, error) {
+	if len(residues) != len(moduli) {
+		return nil, fmt.Errorf("MultiCRT: %d residues for %d moduli", len(residues), len(moduli))
+	}
+	if err := checkModuli("MultiCRT", moduli); err != nil {
+		return nil, err
+	}
+	z.doinit()
+
+	vm := fmpzVec(moduli)
+	defer C._fmpz_vec_clear(vm, C.slong(len(moduli)))
+	vr := fmpzVec(residues)
+	defer C._fmpz_vec_clear(vr, C.slong(len(residues)))
+
+	out := new(Fmpz)
+	out.doinit()
+	if C.compat_multi_crt(&out.i[0], vm, vr, C.slong(len(moduli))) == 1 {
+		m := NewFmpz(1)
+		for _, mi := range moduli {
+			m.Mul(m, mi)
+		}
+		return z.Mod(out, m), nil
+	}
+	return crtFold(z, residues, moduli)
+}
+
+// crtFold combines the congruences one at a time, allowing moduli with common factors, sets z to
+// the result in [0, lcm(moduli)) and returns z.
+func crtFold(z *Fmpz, residues, moduli []*Fmpz) (*Fmpz, error) {
+	x := new(Fmpz).Mod(residues[0], moduli[0])
+	m := new(Fmpz).Set(moduli[0])
+	g, d, t := new(Fmpz), new(Fmpz), new(Fmpz)
+	for i := 1; i < len(moduli); i++ {
+		// Solve x + m*k = r (mod mi): with g = gcd(m, mi) this needs g | r - x and then
+		// k = (r - x)/g * (m/g)^-1 (mod mi/g).
+		mi := moduli[i]
+		g.GCD(m, mi)
+		d.Sub(residues[i], x)
+		if !t.Mod(d, g).IsZero() {
+			return nil, errors.New("MultiCRT: congruences are inconsistent")
+		}
+		mig := new(Fmpz).Quo(mi, g)
+		d.Quo(d, g)
+		if mig.Cmp(NewFmpz(1)) != 0 {
+			t.ModInverse(new(Fmpz).Quo(m, g), mig)
+			d.Mul(d, t).Mod(d, mig)
+		} else {
+			d.SetInt64(0)
+		}
+		x.Add(x, t.Mul(m, d))
+		m.Mul(m, mig)
+		x.Mod(x, m)
+	}
+	return z.Set(x), nil
+}
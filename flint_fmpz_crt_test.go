@@ -0,0 +1,161 @@
+package goflint
+
+import "testing"
+
+// fmpzs converts decimal strings to Fmpz values.
+func fmpzs(t *testing.T, xs ...string) []*Fmpz {
+	var out []*Fmpz
+	for _, x := range xs {
+		z, ok := new(Fmpz).SetString(x, 10)
+		if !ok {
+			t.Fatalf("failed converting %s to Fmpz", x)
+		}
+		out = append(out, z)
+	}
+	return out
+}
+
+func TestMultiCRT(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		residues []string
+		moduli   []string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "hastad broadcast with e = 3",
+			residues: []string{"641320006586229367", "84065080142671765", "583260055377338819"},
+			moduli:   []string{"1000000016000000063", "998244373963131413", "1000000120000002871"},
+			want:     "1881676372353657731338003115679818096684294558605752",
+		},
+		{
+			name:     "single congruence",
+			residues: []string{"-1"},
+			moduli:   []string{"7"},
+			want:     "6",
+		},
+		{
+			name:     "consistent moduli with a common factor",
+			residues: []string{"2", "8"},
+			moduli:   []string{"6", "10"},
+			want:     "8",
+		},
+		{
+			name:     "modulus of one",
+			residues: []string{"5", "3"},
+			moduli:   []string{"1", "7"},
+			want:     "3",
+		},
+		{
+			name:     "inconsistent moduli with a common factor",
+			residues: []string{"1", "2"},
+			moduli:   []string{"6", "4"},
+			wantErr:  true,
+		},
+		{
+			name:     "zero modulus",
+			residues: []string{"1", "2"},
+			moduli:   []string{"0", "4"},
+			wantErr:  true,
+		},
+		{
+			name:     "length mismatch",
+			residues: []string{"1"},
+			moduli:   []string{"3", "4"},
+			wantErr:  true,
+		},
+		{
+			name:    "empty",
+			wantErr: true,
+		},
+	} {
+		got, err := new(Fmpz).MultiCRT(fmpzs(t, tc.residues...), fmpzs(t, tc.moduli...))
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("MultiCRT() %s expected error but got %v", tc.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("MultiCRT() %s got error when not expected: %v", tc.name, err)
+		}
+		if got.String() != tc.want {
+			t.Errorf("MultiCRT() %s want / got mismatch: %s / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestMultiCRTHastad(t *testing.T) {
+	moduli := fmpzs(t, "1000000016000000063", "998244373963131413", "1000000120000002871")
+	residues := fmpzs(t, "641320006586229367", "84065080142671765", "583260055377338819")
+
+	c, err := new(Fmpz).MultiCRT(residues, moduli)
+	if err != nil {
+		t.Fatalf("MultiCRT() got error when not expected: %v", err)
+	}
+	if got := new(Fmpz).Root(c, 3); got.String() != "123456789012345678" {
+		t.Errorf("MultiCRT() cube root want / got mismatch: 123456789012345678 / %v", got)
+	}
+}
+
+func TestCRTContext(t *testing.T) {
+	moduli := fmpzs(t, "1000000016000000063", "998244373963131413", "1000000120000002871")
+	c, err := NewCRTContext(moduli)
+	if err != nil {
+		t.Fatalf("NewCRTContext() got error when not expected: %v", err)
+	}
+
+	if want := "998244509724371117464116787121088789132721681468063549"; c.Modulus().String() != want {
+		t.Errorf("Modulus() want / got mismatch: %s / %v", want, c.Modulus())
+	}
+
+	for _, tc := range []struct {
+		name     string
+		residues []string
+		want     string
+	}{
+		{
+			name:     "hastad broadcast with e = 3",
+			residues: []string{"641320006586229367", "84065080142671765", "583260055377338819"},
+			want:     "1881676372353657731338003115679818096684294558605752",
+		},
+		{
+			name:     "small value",
+			residues: []string{"42", "42", "42"},
+			want:     "42",
+		},
+		{
+			name:     "negative residues",
+			residues: []string{"-1", "-1", "-1"},
+			want:     "998244509724371117464116787121088789132721681468063548",
+		},
+	} {
+		got, err := c.CRT(new(Fmpz), fmpzs(t, tc.residues...))
+		if err != nil {
+			t.Fatalf("CRT() %s got error when not expected: %v", tc.name, err)
+		}
+		if got.String() != tc.want {
+			t.Errorf("CRT() %s want / got mismatch: %s / %v", tc.name, tc.want, got)
+		}
+	}
+
+	if _, err := c.CRT(new(Fmpz), fmpzs(t, "1")); err == nil {
+		t.Error("CRT() expected error for too few residues but got nil")
+	}
+}
+
+func TestNewCRTContextErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		moduli []string
+	}{
+		{name: "common factor", moduli: []string{"6", "10"}},
+		{name: "negative modulus", moduli: []string{"-5", "7"}},
+		{name: "empty"},
+	} {
+		if _, err := NewCRTContext(fmpzs(t, tc.moduli...)); err == nil {
+			t.Errorf("NewCRTContext() %s expected error but got nil", tc.name)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package goflint
+
+/*
+#include <flint/flint.h>
+#include <flint/fmpz.h>
+#if __FLINT_RELEASE >= 20700
+#include <flint/fmpz_mod.h>
+#endif
+
+// Helper function for FLINT 2/3 compatibility. FLINT 2.7 added a Pohlig-Hellman solver which
+// returns logarithms to its own primitive root modulo the prime p. Take the logarithms lg and lh
+// of g and h to that root, or return -1 on older releases so the caller uses its own solver.
+static int compat_discrete_log_pohlig_hellman(fmpz_t lg, fmpz_t lh, const fmpz_t p, const fmpz_t g, const fmpz_t h) {
+    #if __FLINT_RELEASE >= 20700
+        fmpz_mod_discrete_log_pohlig_hellman_t L;
+        fmpz_mod_discrete_log_pohlig_hellman_init(L);
+        fmpz_mod_discrete_log_pohlig_hellman_precompute_prime(L, p);
+        fmpz_mod_discrete_log_pohlig_hellman_run(lg, L, g);
+        fmpz_mod_discrete_log_pohlig_hellman_run(lh, L, h);
+        fmpz_mod_discrete_log_pohlig_hellman_clear(L);
+        return 1;
+    #else
+        return -1;
+    #endif
+}
+*/
+import "C"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	// bsgsMaxTableBytes bounds the total size of the keys in the baby-step table of
+	// DiscreteLogBSGS. Each key is a residue modulo n, so the number of baby steps, and with it
+	// the square root of the widest interval that can be searched, shrinks as n grows.
+	bsgsMaxTableBytes = 1 << 29
+	// kangarooTries is the number of jump sets DiscreteLogKangaroo tries before giving up.
+	kangarooTries = 8
+)
+
+// dlogUnits reduces g and h modulo n, returning an error if n < 2 or either is not a unit.
+func dlogUnits(fn string, g, h, n *Fmpz) (*Fmpz, *Fmpz, error) {
+	if n.Cmp(NewFmpz(2)) < 0 {
+		return nil, nil, fmt.Errorf("%s: modulus %v is less than 2", fn, n)
+	}
+	one := NewFmpz(1)
+	gr := new(Fmpz).Mod(g, n)
+	hr := new(Fmpz).Mod(h, n)
+	if !new(Fmpz).GCD(gr, n).Equals(one) {
+		return nil, nil, fmt.Errorf("%s: base %v is not a unit modulo %v", fn, g, n)
+	}
+	if !new(Fmpz).GCD(hr, n).Equals(one) {
+		return nil, nil, fmt.Errorf("%s: %v is not a unit modulo %v", fn, h, n)
+	}
+	return gr, hr, nil
+}
+
+// errTooWide is wrapped by the error DiscreteLogBSGS returns when the interval is too wide to
+// search, so callers can tell it apart from h not being a power of g.
+var errTooWide = errors.New("interval too wide to search")
+
+// noLog returns the error reported when h is not a power of g modulo n.
+func noLog(fn string, g, h, n *Fmpz) error {
+	return fmt.Errorf("%s: %v is not a power of %v modulo %v", fn, h, g, n)
+}
+
+// DiscreteLog sets z to the least x >= 0 with g^x = h (mod p) for the prime p and returns z. This
+// uses the Pohlig-Hellman algorithm, which factors p-1 and takes time proportional to the square
+// root of its largest prime factor, so it is only practical when p-1 is smooth. An error is
+// returned if p is not a probable prime, if g or h is not a unit modulo p, or if h is not a power
+// of g. The result is checked before it is returned.
+func (z *Fmpz) DiscreteLog(g, h, p *Fmpz) (*Fmpz, error) {
+	gr, hr, err := dlogUnits("DiscreteLog", g, h, p)
+	if err != nil {
+		return nil, err
+	}
+	if p.IsProbabPrime() == 0 {
+		return nil, fmt.Errorf("DiscreteLog: modulus %v is not prime", p)
+	}
+	z.doinit()
+	if p.Cmp(NewFmpz(2)) == 0 {
+		return z.SetInt64(0), nil
+	}
+
+	lg, lh := new(Fmpz), new(Fmpz)
+	lg.doinit()
+	lh.doinit()
+	if C.compat_discrete_log_pohlig_hellman(&lg.i[0], &lh.i[0], &p.i[0], &gr.i[0], &hr.i[0]) == -1 {
+		pm1 := new(Fmpz).Sub(p, NewFmpz(1))
+		x, err := new(Fmpz).DiscreteLogPH(gr, hr, p, pm1.Factor())
+		if errors.Is(err, errTooWide) {
+			return nil, fmt.Errorf("DiscreteLog: %w", err)
+		}
+		if err != nil {
+			return nil, noLog("DiscreteLog", g, h, p)
+		}
+		return z.Set(x), nil
+	}
+
+	// With a primitive root r, g = r^lg and h = r^lh, so x solves lg*x = lh (mod p-1). Dividing
+	// through by d = gcd(lg, p-1) leaves the order of g, (p-1)/d, as the modulus.
+	pm1 := new(Fmpz).Sub(p, NewFmpz(1))
+	d := new(Fmpz).GCD(lg, pm1)
+	if !new(Fmpz).Mod(lh, d).IsZero() {
+		return nil, noLog("DiscreteLog", g, h, p)
+	}
+	ord := new(Fmpz).Quo(pm1, d)
+	x := new(Fmpz)
+	if ord.Cmp(NewFmpz(1)) != 0 {
+		x.ModInverse(lg.Quo(lg, d), ord).MulZ(lh.Quo(lh, d)).ModZ(ord)
+	}
+	if !new(Fmpz).Exp(gr, x, p).Equals(hr) {
+		return nil, noLog("DiscreteLog", g, h, p)
+	}
+	return z.Set(x), nil
+}
+
+// DiscreteLogPH sets z to the least x >= 0 with g^x = h (mod n) and returns z, using the
+// Pohlig-Hellman algorithm for a group of smooth order. The modulus n need not be prime: order is
+// the factorization of any multiple N of the order of g, such as p-1 for a prime p or the
+// Carmichael function of n. The logarithm is found modulo each prime power q^e dividing N by
+// baby-step giant-step on the digits base q and combined by CRT, so the time is proportional to
+// the square root of the largest q. An error is returned if g or h is not a unit modulo n, if
+// g^N != 1 (mod n), if some q is too large for DiscreteLogBSGS, or if h is not a power of g.
+func (z *Fmpz) DiscreteLogPH(g, h, n *Fmpz, order *FmpzFactor) (*Fmpz, error) {
+	gr, hr, err := dlogUnits("DiscreteLogPH", g, h, n)
+	if err != nil {
+		return nil, err
+	}
+	moduli, err := factorPrimePowers("DiscreteLogPH", order)
+	if err != nil {
+		return nil, err
+	}
+	one := NewFmpz(1)
+	bigN := NewFmpz(1)
+	for _, m := range moduli {
+		bigN.MulZ(m)
+	}
+	if !new(Fmpz).Exp(gr, bigN, n).Equals(one) {
+		return nil, fmt.Errorf("DiscreteLogPH: %v^%v is not 1 modulo %v", g, bigN, n)
+	}
+
+	var residues, orders []*Fmpz
+	for i, q := range order.Primes() {
+		c := new(Fmpz).Quo(bigN, moduli[i])
+		gq := new(Fmpz).Exp(gr, c, n)
+		hq := new(Fmpz).Exp(hr, c, n)
+
+		// gq has order q^f for some f <= e. Find f, and check h lies in the same subgroup.
+		f := 0
+		for t := new(Fmpz).Set(gq); !t.Equals(one); f++ {
+			t.Exp(t, q, n)
+		}
+		qf := new(Fmpz).ExpXI(q, f)
+		if !new(Fmpz).Exp(hq, qf, n).Equals(one) {
+			return nil, noLog("DiscreteLogPH", g, h, n)
+		}
+
+		// Find x = d_0 + d_1*q + ... one digit at a time in the subgroup of order q generated by
+		// gamma = gq^(q^(f-1)).
+		x := new(Fmpz)
+		if f > 0 {
+			gamma := new(Fmpz).Exp(gq, new(Fmpz).ExpXI(q, f-1), n)
+			ginv := new(Fmpz).ModInverse(gq, n)
+			qk := NewFmpz(1)
+			hk := new(Fmpz)
+			for k := 0; k < f; k++ {
+				hk.Exp(ginv, x, n).MulZ(hq).ModZ(n)
+				hk.Exp(hk, new(Fmpz).ExpXI(q, f-1-k), n)
+				d, err := new(Fmpz).DiscreteLogBSGS(gamma, hk, n, new(Fmpz), q)
+				if errors.Is(err, errTooWide) {
+					return nil, fmt.Errorf("DiscreteLogPH: order has prime factor %v: %w", q, err)
+				}
+				if err != nil {
+					return nil, noLog("DiscreteLogPH", g, h, n)
+				}
+				x.Add(x, d.MulZ(qk))
+				qk.MulZ(q)
+			}
+		}
+		residues = append(residues, x)
+		orders = append(orders, qf)
+	}
+
+	x := new(Fmpz)
+	if len(residues) > 0 {
+		if _, err := x.MultiCRT(residues, orders); err != nil {
+			return nil, err
+		}
+	}
+	x.ModZ(multiplicativeOrder(gr, n, bigN, order))
+	if !new(Fmpz).Exp(gr, x, n).Equals(hr) {
+		return nil, noLog("DiscreteLogPH", g, h, n)
+	}
+	return z.Set(x), nil
+}
+
+// multiplicativeOrder returns the order of the unit g modulo n, given that g^N = 1 (mod n) where
+// fac is the factorization of N.
+func multiplicativeOrder(g, n, bigN *Fmpz, fac *FmpzFactor) *Fmpz {
+	o := new(Fmpz).Set(bigN)
+	one := NewFmpz(1)
+	t := new(Fmpz)
+	for i, q := range fac.Primes() {
+		for e := fac.GetExp(i); e > 0; e-- {
+			t.Quo(o, q)
+			if !new(Fmpz).Exp(g, t, n).Equals(one) {
+				break
+			}
+			o.Set(t)
+		}
+	}
+	return o
+}
+
+// DiscreteLogBSGS sets z to the least x in the interval [a, b) with g^x = h (mod n) and returns z,
+// using Shanks' baby-step giant-step algorithm. This takes time and memory proportional to the
+// square root of b-a. An error is returned if g or h is not a unit modulo n, if there is no such
+// x, or if the interval is too wide to search.
+func (z *Fmpz) DiscreteLogBSGS(g, h, n, a, b *Fmpz) (*Fmpz, error) {
+	gr, hr, err := dlogUnits("DiscreteLogBSGS", g, h, n)
+	if err != nil {
+		return nil, err
+	}
+	w := new(Fmpz).Sub(b, a)
+	if w.Sign() <= 0 {
+		return nil, fmt.Errorf("DiscreteLogBSGS: empty interval [%v, %v)", a, b)
+	}
+
+	// Write x = a + i*m + j with 0 <= j < m, so g^j = h * g^-a * (g^-m)^i.
+	m := new(Fmpz).Sqrt(new(Fmpz).Sub(w, NewFmpz(1))).AddI(1)
+	if new(Fmpz).Set(m).MulI(len(n.Bytes())).Cmp(NewFmpz(bsgsMaxTableBytes)) > 0 {
+		return nil, fmt.Errorf("DiscreteLogBSGS: width %v: %w", w, errTooWide)
+	}
+	steps := int(m.Int64())
+
+	baby := make(map[string]int64, steps)
+	x := NewFmpz(1)
+	for j := 0; j < steps; j++ {
+		key := string(x.Bytes())
+		if _, ok := baby[key]; !ok {
+			baby[key] = int64(j)
+		}
+		x.MulZ(gr).ModZ(n)
+	}
+
+	ginv := new(Fmpz).ModInverse(gr, n)
+	giant := new(Fmpz).Exp(ginv, m, n)
+	y := new(Fmpz)
+	if a.Sign() < 0 {
+		y.Exp(gr, new(Fmpz).Neg(a), n)
+	} else {
+		y.Exp(ginv, a, n)
+	}
+	y.MulZ(hr).ModZ(n)
+	for i := new(Fmpz); new(Fmpz).Mul(i, m).Cmp(w) < 0; i.AddI(1) {
+		if j, ok := baby[string(y.Bytes())]; ok {
+			x.Mul(i, m).AddZ(a).AddI(int(j))
+			if x.Cmp(b) >= 0 {
+				break
+			}
+			return z.Set(x), nil
+		}
+		y.MulZ(giant).ModZ(n)
+	}
+	return nil, noLog("DiscreteLogBSGS", g, h, n)
+}
+
+// DiscreteLogKangaroo sets z to some x in the interval [a, b) with g^x = h (mod n) and returns z,
+// using Pollard's kangaroo (lambda) method. This takes time proportional to the square root of
+// b-a but, unlike DiscreteLogBSGS, almost no memory. The method is probabilistic: each of a fixed
+// number of jump sets fails with small probability, after which an error is returned even if x
+// exists. ctx is checked periodically so a long search can be cancelled, in which case the
+// context error is returned.
+func (z *Fmpz) DiscreteLogKangaroo(ctx context.Context, g, h, n, a, b *Fmpz) (*Fmpz, error) {
+	gr, hr, err := dlogUnits("DiscreteLogKangaroo", g, h, n)
+	if err != nil {
+		return nil, err
+	}
+	w := new(Fmpz).Sub(b, a)
+	if w.Sign() <= 0 {
+		return nil, fmt.Errorf("DiscreteLogKangaroo: empty interval [%v, %v)", a, b)
+	}
+	if a.Sign() < 0 {
+		// Search [0, b-a) for the logarithm of h * g^-a instead.
+		ha := new(Fmpz).Exp(gr, new(Fmpz).Neg(a), n)
+		x, err := new(Fmpz).DiscreteLogKangaroo(ctx, gr, ha.MulZ(hr).ModZ(n), n, new(Fmpz), w)
+		if err != nil {
+			return nil, err
+		}
+		return z.Add(x, a), nil
+	}
+
+	// Jumps of roughly 2^i for i < k have mean distance (2^k-1)/k, and Pollard's choice of the
+	// least k with (2^k-1)/k >= sqrt(w)/2 keeps both kangaroos to O(sqrt(w)) jumps. The tame
+	// kangaroo runs 2*sqrt(w) jumps from b and sets a trap; the wild kangaroo starts from h and
+	// jumps until it lands in the trap or has passed it.
+	sqrtW := new(Fmpz).Sqrt(w).AddI(1)
+	k := 1
+	for new(Fmpz).SetBit(k+1).SubI(2).Cmp(new(Fmpz).Set(sqrtW).MulI(k)) < 0 {
+		k++
+	}
+	tameSteps := new(Fmpz).Add(sqrtW, sqrtW)
+
+	jumps := make([]*Fmpz, k)
+	powers := make([]*Fmpz, k)
+	next := func(y, d *Fmpz) {
+		i := int(C.fmpz_fdiv_ui(&y.i[0], C.ulong(k)))
+		y.MulZ(powers[i]).ModZ(n)
+		d.AddZ(jumps[i])
+	}
+
+	count := 0
+	cancelled := func() error {
+		if count++; count%(1<<12) == 0 {
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	for try := 0; try < kangarooTries; try++ {
+		for i := range jumps {
+			jumps[i] = new(Fmpz).SetBit(i).AddI(try)
+			powers[i] = new(Fmpz).Exp(gr, jumps[i], n)
+		}
+
+		trap := new(Fmpz).Exp(gr, b, n)
+		dT := new(Fmpz)
+		for s := new(Fmpz); s.Cmp(tameSteps) < 0; s.AddI(1) {
+			if err := cancelled(); err != nil {
+				return nil, err
+			}
+			next(trap, dT)
+		}
+
+		y := new(Fmpz).Set(hr)
+		dW := new(Fmpz)
+		limit := new(Fmpz).Add(w, dT)
+		for dW.Cmp(limit) <= 0 {
+			if err := cancelled(); err != nil {
+				return nil, err
+			}
+			if y.Equals(trap) {
+				x := new(Fmpz).Add(b, dT).SubZ(dW)
+				if x.Cmp(a) >= 0 && x.Cmp(b) < 0 && new(Fmpz).Exp(gr, x, n).Equals(hr) {
+					return z.Set(x), nil
+				}
+				break
+			}
+			next(y, dW)
+		}
+	}
+	return nil, errors.New("DiscreteLogKangaroo: no logarithm found in the interval")
+}
@@ -0,0 +1,249 @@
+package goflint
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// smoothPrime is a prime p with p-1 = 20 * (product of the primes below 100).
+const smoothPrime = "46111359278910368495062042946635121401"
+
+func TestDiscreteLog(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		g    string
+		x    string
+		p    string
+		want string
+	}{
+		{
+			name: "smooth prime",
+			g:    "3",
+			x:    "10000000000000000000012345",
+			p:    smoothPrime,
+			want: "10000000000000000000012345",
+		},
+		{
+			name: "exponent beyond the order of g",
+			g:    "4",
+			x:    "316995000368134854638000075265570",
+			p:    smoothPrime,
+			want: "10000000000000000000012345",
+		},
+		{
+			name: "p-1 with a large prime factor",
+			g:    "5",
+			x:    "123456789",
+			p:    "1000000007",
+			want: "123456789",
+		},
+		{
+			name: "trivial exponent",
+			g:    "5",
+			x:    "0",
+			p:    "1000000007",
+			want: "0",
+		},
+	} {
+		g, _ := new(Fmpz).SetString(tc.g, 10)
+		x, _ := new(Fmpz).SetString(tc.x, 10)
+		p, _ := new(Fmpz).SetString(tc.p, 10)
+		h := new(Fmpz).Exp(g, x, p)
+
+		got, err := new(Fmpz).DiscreteLog(g, h, p)
+		if err != nil {
+			t.Fatalf("DiscreteLog() %s got error when not expected: %v", tc.name, err)
+		}
+		if got.String() != tc.want {
+			t.Errorf("DiscreteLog() %s want / got mismatch: %s / %v", tc.name, tc.want, got)
+		}
+	}
+
+	p := NewFmpz(1000000007)
+	for _, tc := range []struct {
+		name string
+		g    int64
+		h    int64
+	}{
+		{
+			name: "non residue is not a power of a residue",
+			g:    4,
+			h:    5,
+		},
+		{
+			name: "zero is not a unit",
+			g:    5,
+			h:    0,
+		},
+	} {
+		if got, err := new(Fmpz).DiscreteLog(NewFmpz(tc.g), NewFmpz(tc.h), p); err == nil {
+			t.Errorf("DiscreteLog() %s expected error but got %v", tc.name, got)
+		}
+	}
+
+	if got, err := new(Fmpz).DiscreteLog(NewFmpz(2), NewFmpz(4), NewFmpz(77)); err == nil {
+		t.Errorf("DiscreteLog() expected error for a composite modulus but got %v", got)
+	}
+}
+
+func TestDiscreteLogPH(t *testing.T) {
+	p, _ := new(Fmpz).SetString(smoothPrime, 10)
+	for _, tc := range []struct {
+		name  string
+		g     *Fmpz
+		x     string
+		n     *Fmpz
+		order *FmpzFactor
+		want  string
+	}{
+		{
+			name:  "smooth prime",
+			g:     NewFmpz(3),
+			x:     "10000000000000000000012345",
+			n:     p,
+			order: new(Fmpz).Sub(p, NewFmpz(1)).Factor(),
+			want:  "10000000000000000000012345",
+		},
+		{
+			// The order of 2 modulo 77 is 30 = lcm(3, 10).
+			name:  "composite modulus",
+			g:     NewFmpz(2),
+			x:     "47",
+			n:     NewFmpz(77),
+			order: NewFmpz(30).Factor(),
+			want:  "17",
+		},
+		{
+			// 1056 = lcm(96, 88) is the Carmichael function of 8633 = 97 * 89.
+			name:  "Carmichael function of a composite modulus",
+			g:     NewFmpz(10),
+			x:     "200",
+			n:     NewFmpz(97 * 89),
+			order: NewFmpz(1056).Factor(),
+			want:  "200",
+		},
+	} {
+		x, _ := new(Fmpz).SetString(tc.x, 10)
+		h := new(Fmpz).Exp(tc.g, x, tc.n)
+
+		got, err := new(Fmpz).DiscreteLogPH(tc.g, h, tc.n, tc.order)
+		if err != nil {
+			t.Fatalf("DiscreteLogPH() %s got error when not expected: %v", tc.name, err)
+		}
+		if got.String() != tc.want {
+			t.Errorf("DiscreteLogPH() %s want / got mismatch: %s / %v", tc.name, tc.want, got)
+		}
+	}
+
+	if _, err := new(Fmpz).DiscreteLogPH(NewFmpz(2), NewFmpz(4), NewFmpz(77), NewFmpz(7).Factor()); err == nil {
+		t.Error("DiscreteLogPH() expected error for an order not killing g but got none")
+	}
+
+	// 4 has prime order q = 2305843009213697249 modulo the safe prime 2q+1, too large for the
+	// baby-step giant-step digits.
+	sp, _ := new(Fmpz).SetString("4611686018427394499", 10)
+	h := new(Fmpz).Exp(NewFmpz(4), NewFmpz(12345), sp)
+	order := new(Fmpz).Sub(sp, NewFmpz(1)).Factor()
+	if _, err := new(Fmpz).DiscreteLogPH(NewFmpz(4), h, sp, order); !errors.Is(err, errTooWide) {
+		t.Errorf("DiscreteLogPH() want / got error mismatch: %v / %v", errTooWide, err)
+	}
+}
+
+func TestDiscreteLogInterval(t *testing.T) {
+	p := NewFmpz(1000000007)
+	for _, tc := range []struct {
+		name    string
+		g       int64
+		x       int64
+		a       int64
+		b       int64
+		wantErr bool
+	}{
+		{
+			name: "wide interval",
+			g:    5,
+			x:    123456789,
+			a:    100000000,
+			b:    200000000,
+		},
+		{
+			name: "exponent at the start of the interval",
+			g:    5,
+			x:    1000,
+			a:    1000,
+			b:    1000000,
+		},
+		{
+			name: "exponent at the end of the interval",
+			g:    5,
+			x:    999999,
+			a:    1000,
+			b:    1000000,
+		},
+		{
+			name: "interval below zero",
+			g:    5,
+			x:    -20,
+			a:    -100,
+			b:    100,
+		},
+		{
+			name:    "exponent outside the interval",
+			g:       5,
+			x:       5000,
+			a:       0,
+			b:       4000,
+			wantErr: true,
+		},
+	} {
+		g := NewFmpz(tc.g)
+		x := NewFmpz(tc.x)
+		a, b := NewFmpz(tc.a), NewFmpz(tc.b)
+		var h *Fmpz
+		if tc.x < 0 {
+			h = new(Fmpz).Exp(new(Fmpz).ModInverse(g, p), new(Fmpz).Neg(x), p)
+		} else {
+			h = new(Fmpz).Exp(g, x, p)
+		}
+
+		got, err := new(Fmpz).DiscreteLogBSGS(g, h, p, a, b)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("DiscreteLogBSGS() %s expected error but got %v", tc.name, got)
+			}
+		} else if err != nil || !got.Equals(x) {
+			t.Errorf("DiscreteLogBSGS() %s want / got mismatch: %v / %v (err %v)", tc.name, x, got, err)
+		}
+
+		got, err = new(Fmpz).DiscreteLogKangaroo(context.Background(), g, h, p, a, b)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("DiscreteLogKangaroo() %s expected error but got %v", tc.name, got)
+			}
+		} else if err != nil || !got.Equals(x) {
+			t.Errorf("DiscreteLogKangaroo() %s want / got mismatch: %v / %v (err %v)", tc.name, x, got, err)
+		}
+	}
+}
+
+func TestDiscreteLogBSGSTooWide(t *testing.T) {
+	// The table for a width of 2^51 needs about 2^25.5 baby steps of 16 bytes each modulo
+	// smoothPrime, which is over the limit.
+	p, _ := new(Fmpz).SetString(smoothPrime, 10)
+	b := new(Fmpz).SetBit(51)
+	if _, err := new(Fmpz).DiscreteLogBSGS(NewFmpz(3), NewFmpz(7), p, new(Fmpz), b); !errors.Is(err, errTooWide) {
+		t.Errorf("DiscreteLogBSGS() want / got error mismatch: %v / %v", errTooWide, err)
+	}
+}
+
+func TestDiscreteLogKangarooCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p, _ := new(Fmpz).SetString(smoothPrime, 10)
+	b := new(Fmpz).SetBit(80)
+	if _, err := new(Fmpz).DiscreteLogKangaroo(ctx, NewFmpz(3), NewFmpz(7), p, new(Fmpz), b); err != context.Canceled {
+		t.Errorf("DiscreteLogKangaroo() want / got error mismatch: %v / %v", context.Canceled, err)
+	}
+}
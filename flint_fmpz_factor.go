@@ -0,0 +1,451 @@
+package goflint
+
+/*
+#include <flint/flint.h>
+#include <flint/fmpz.h>
+#include <flint/fmpz_factor.h>
+#include <flint/ulong_extras.h>
+#include <stdlib.h>
+
+// Macros
+
+int fmpz_factor_get_sign(fmpz_factor_t fac) {
+	return fac->sign;
+}
+
+slong fmpz_factor_get_num(fmpz_factor_t fac) {
+	return fac->num;
+}
+
+fmpz *fmpz_factor_get_p(fmpz_factor_t fac, slong i) {
+	return fac->p + i;
+}
+
+ulong fmpz_factor_get_exp(fmpz_factor_t fac, slong i) {
+	return fac->exp[i];
+}
+
+// Williams p+1 is only available in newer FLINT releases, return -1 to signal that the caller
+// must fall back to its own implementation.
+int compat_fmpz_factor_pp1(fmpz_t factor, const fmpz_t n, ulong B1, ulong B2_sqrt, ulong c) {
+	#if __FLINT_RELEASE >= 20400
+		return fmpz_factor_pp1(factor, n, B1, B2_sqrt, c);
+	#else
+		return -1;
+	#endif
+}
+
+*/
+import "C"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// FmpzFactor type represents the factorization of an integer into its sign and a list of
+// prime factors with their exponents.
+type FmpzFactor struct {
+	i    C.fmpz_factor_t
+	init bool
+}
+
+// fmpzFactorFinalize releases the memory allocated to the FmpzFactor.
+func fmpzFactorFinalize(f *FmpzFactor) {
+	if f.init {
+		runtime.SetFinalizer(f, nil)
+		C.fmpz_factor_clear(&f.i[0])
+		f.init = false
+	}
+}
+
+// fmpzFactorDoinit initializes an FmpzFactor type.
+func (f *FmpzFactor) fmpzFactorDoinit() {
+	if f.init {
+		return
+	}
+	f.init = true
+	C.fmpz_factor_init(&f.i[0])
+	runtime.SetFinalizer(f, fmpzFactorFinalize)
+}
+
+// NewFmpzFactor allocates a new FmpzFactor and returns it.
+func NewFmpzFactor() *FmpzFactor {
+	f := new(FmpzFactor)
+	f.fmpzFactorDoinit()
+	return f
+}
+
+// Factor factors z into primes using trial division, perfect power detection, Pollard rho and
+// the elliptic curve method as needed, and returns the factorization in an FmpzFactor type.
+// The factorization of 0 has sign 0 and no factors, the factorization of 1 has sign 1 and no
+// factors.
+func (z *Fmpz) Factor() *FmpzFactor {
+	z.doinit()
+	fac := NewFmpzFactor()
+	C.fmpz_factor(&fac.i[0], &z.i[0])
+	return fac
+}
+
+// Append adds the prime p with exponent exp to the factorization and returns f. This builds a
+// known factorization, such as that of an RSA modulus, without factoring. The primes appended
+// must be distinct and exp must be positive.
+func (f *FmpzFactor) Append(p *Fmpz, exp int) *FmpzFactor {
+	if exp <= 0 {
+		panic("Append: exponent must be positive")
+	}
+	f.fmpzFactorDoinit()
+	p.doinit()
+	C._fmpz_factor_append(&f.i[0], &p.i[0], C.ulong(exp))
+	return f
+}
+
+// Sign returns the sign of the factored integer as -1, 0 or 1.
+func (f *FmpzFactor) Sign() int {
+	f.fmpzFactorDoinit()
+	return int(C.fmpz_factor_get_sign(&f.i[0]))
+}
+
+// Len gets the number of distinct prime factors in the FmpzFactor.
+func (f *FmpzFactor) Len() int {
+	f.fmpzFactorDoinit()
+	return int(C.fmpz_factor_get_num(&f.i[0]))
+}
+
+// GetPrime gets the nth prime factor from the FmpzFactor and returns it.
+func (f *FmpzFactor) GetPrime(n int) *Fmpz {
+	f.fmpzFactorDoinit()
+	z := new(Fmpz)
+	z.doinit()
+	C.fmpz_set(&z.i[0], C.fmpz_factor_get_p(&f.i[0], C.slong(n)))
+	return z
+}
+
+// GetExp gets the exponent of the nth prime factor from the FmpzFactor.
+func (f *FmpzFactor) GetExp(n int) int {
+	f.fmpzFactorDoinit()
+	return int(C.fmpz_factor_get_exp(&f.i[0], C.slong(n)))
+}
+
+// Primes returns all of the prime factors in the FmpzFactor in increasing order.
+func (f *FmpzFactor) Primes() []*Fmpz {
+	var primes []*Fmpz
+	for i := 0; i < f.Len(); i++ {
+		primes = append(primes, f.GetPrime(i))
+	}
+	return primes
+}
+
+// Exponents returns the exponents of the prime factors in the same order as Primes.
+func (f *FmpzFactor) Exponents() []int {
+	var exps []int
+	for i := 0; i < f.Len(); i++ {
+		exps = append(exps, f.GetExp(i))
+	}
+	return exps
+}
+
+// String returns a string representation of the factorization, e.g. 1960 is "2^3 * 5 * 7^2".
+// A negative sign is written as a leading "-1 * " and an empty factorization is "1" or "0".
+func (f *FmpzFactor) String() string {
+	if f == nil {
+		return "<nil>"
+	}
+
+	if f.Sign() == 0 {
+		return "0"
+	}
+
+	var terms []string
+	if f.Sign() < 0 {
+		terms = append(terms, "-1")
+	}
+
+	for i := 0; i < f.Len(); i++ {
+		if e := f.GetExp(i); e != 1 {
+			terms = append(terms, fmt.Sprintf("%v^%d", f.GetPrime(i), e))
+			continue
+		}
+		terms = append(terms, f.GetPrime(i).String())
+	}
+
+	if len(terms) == 0 {
+		return "1"
+	}
+
+	return strings.Join(terms, " * ")
+}
+
+// FactorECM searches for a non-trivial factor of z using the elliptic curve method with stage 1
+// bound b1 and stage 2 bound b2. Up to curves curves are tried one at a time, drawing the curve
+// parameters from state, and ctx is checked between curves so a long search can be cancelled.
+// The factor found is returned, or nil if no factor was found on any of the curves. If ctx is
+// cancelled before a factor is found, nil and the context error are returned.
+func (z *Fmpz) FactorECM(ctx context.Context, state *FlintRandT, b1, b2 uint64, curves int) (*Fmpz, error) {
+	z.doinit()
+	state.flintRandTDoinit()
+	if b1 < 2 || b2 < b1 {
+		return nil, errors.New("FactorECM: bounds must satisfy 2 <= b1 <= b2")
+	}
+
+	n := new(Fmpz).Abs(z)
+	if n.Cmp(NewFmpz(3)) <= 0 {
+		return nil, nil
+	}
+	if n.TstBit(0) == 0 {
+		return NewFmpz(2), nil
+	}
+
+	f := new(Fmpz)
+	f.doinit()
+	for i := 0; i < curves; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if C.fmpz_factor_ecm(&f.i[0], 1, C.mp_limb_t(b1), C.mp_limb_t(b2), &state.i[0], &n.i[0]) == 0 {
+			continue
+		}
+
+		// A curve may occasionally reveal every prime at once and return n itself.
+		if f.Cmp(NewFmpz(1)) > 0 && !f.Equals(n) {
+			return f, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FactorPollardBrent searches for a non-trivial factor of z using Pollard's rho algorithm with
+// Brent's cycle detection. Each attempt runs for at most maxIters iterations with a random
+// polynomial drawn from state, and up to maxTries attempts are made. The factor found is
+// returned, or nil if no factor was found.
+func (z *Fmpz) FactorPollardBrent(state *FlintRandT, maxTries, maxIters uint64) *Fmpz {
+	z.doinit()
+	state.flintRandTDoinit()
+
+	n := new(Fmpz).Abs(z)
+	if n.Cmp(NewFmpz(3)) <= 0 {
+		return nil
+	}
+	if n.TstBit(0) == 0 {
+		return NewFmpz(2)
+	}
+
+	f := new(Fmpz)
+	f.doinit()
+	if C.fmpz_factor_pollard_brent(&f.i[0], &state.i[0], &n.i[0], C.mp_limb_t(maxTries), C.mp_limb_t(maxIters)) == 0 {
+		return nil
+	}
+
+	if f.Cmp(NewFmpz(1)) <= 0 || f.Equals(n) {
+		return nil
+	}
+
+	return f
+}
+
+// pm1Batch is the number of primes processed by FactorPM1 between gcd checks.
+const pm1Batch = 64
+
+// nextPrimeUI returns the smallest prime greater than p.
+func nextPrimeUI(p uint64) uint64 {
+	return uint64(C.n_nextprime(C.ulong(p), 1))
+}
+
+// FactorPM1 searches for a non-trivial factor of z using Pollard's p-1 method. This finds a prime
+// factor p of z when every prime power dividing p-1 is at most b1, except for at most one prime
+// which may be as large as b2. Stage 2 is skipped when b2 <= b1. The factor found is returned, or
+// nil if no factor was found, including the case where every prime factor of z is found at once.
+func (z *Fmpz) FactorPM1(b1, b2 uint64) *Fmpz {
+	z.doinit()
+
+	n := new(Fmpz).Abs(z)
+	if n.Cmp(NewFmpz(3)) <= 0 {
+		return nil
+	}
+	if n.TstBit(0) == 0 {
+		return NewFmpz(2)
+	}
+
+	// Stage 1: a = 2^E mod n where E is the product of all maximal prime powers <= b1. The gcd is
+	// only taken once per batch, so keep a checkpoint to replay the batch one prime at a time if
+	// every factor drops out together.
+	a := NewFmpz(2)
+	saved := new(Fmpz).Set(a)
+	savedP := uint64(1)
+	g := new(Fmpz)
+	am1 := new(Fmpz)
+
+	check := func(p uint64) (*Fmpz, bool) {
+		g.GCD(am1.Set(a).SubI(1), n)
+		switch {
+		case g.Cmp(NewFmpz(1)) == 0:
+			saved.Set(a)
+			savedP = p
+			return nil, false
+		case !g.Equals(n):
+			return new(Fmpz).Set(g), true
+		}
+
+		a.Set(saved)
+		for q := nextPrimeUI(savedP); q <= p; q = nextPrimeUI(q) {
+			C.fmpz_powm_ui(&a.i[0], &a.i[0], C.ulong(maxPrimePower(q, b1)), &n.i[0])
+			g.GCD(am1.Set(a).SubI(1), n)
+			if g.Equals(n) {
+				return nil, true
+			}
+			if g.Cmp(NewFmpz(1)) != 0 {
+				return new(Fmpz).Set(g), true
+			}
+		}
+		return nil, true
+	}
+
+	count := 0
+	last := uint64(1)
+	for p := nextPrimeUI(1); p <= b1; p = nextPrimeUI(p) {
+		C.fmpz_powm_ui(&a.i[0], &a.i[0], C.ulong(maxPrimePower(p, b1)), &n.i[0])
+		last = p
+		if count++; count%pm1Batch != 0 {
+			continue
+		}
+		if f, done := check(p); done {
+			return f
+		}
+	}
+	if f, done := check(last); done {
+		return f
+	}
+
+	if b2 <= b1 {
+		return nil
+	}
+
+	// Stage 2: accumulate the product of a^q - 1 for each prime b1 < q <= b2, stepping between
+	// consecutive primes with cached powers a^d for the prime gaps d. As in stage 1, keep a
+	// checkpoint at the start of each batch to replay it one prime at a time if every factor
+	// drops out together.
+	q := nextPrimeUI(b1)
+	if q > b2 {
+		return nil
+	}
+	x := new(Fmpz)
+	x.doinit()
+	C.fmpz_powm_ui(&x.i[0], &a.i[0], C.ulong(q), &n.i[0])
+	gaps := make(map[uint64]*Fmpz)
+	step := func(from, to uint64) {
+		d := to - from
+		ad, ok := gaps[d]
+		if !ok {
+			ad = new(Fmpz)
+			ad.doinit()
+			C.fmpz_powm_ui(&ad.i[0], &a.i[0], C.ulong(d), &n.i[0])
+			gaps[d] = ad
+		}
+		x.MulZ(ad).ModZ(n)
+	}
+
+	acc := NewFmpz(1)
+	savedX := new(Fmpz).Set(x)
+	savedQ := q
+	count = 0
+	for {
+		acc.MulZ(am1.Set(x).SubI(1)).ModZ(n)
+		next := nextPrimeUI(q)
+		checked := false
+		if count++; count%pm1Batch == 0 || next > b2 {
+			g.GCD(acc, n)
+			if g.Equals(n) {
+				x.Set(savedX)
+				for r := savedQ; ; r = nextPrimeUI(r) {
+					g.GCD(am1.Set(x).SubI(1), n)
+					if g.Cmp(NewFmpz(1)) != 0 && !g.Equals(n) {
+						return g
+					}
+					if r == q {
+						return nil
+					}
+					step(r, nextPrimeUI(r))
+				}
+			}
+			if g.Cmp(NewFmpz(1)) != 0 {
+				return g
+			}
+			checked = true
+		}
+		if next > b2 {
+			return nil
+		}
+
+		step(q, next)
+		q = next
+		if checked {
+			savedX.Set(x)
+			savedQ = q
+		}
+	}
+}
+
+// maxPrimePower returns the largest power of the prime p which is at most b, or p if p > b.
+func maxPrimePower(p, b uint64) uint64 {
+	pk := p
+	for pk <= b/p {
+		pk *= p
+	}
+	return pk
+}
+
+// FactorPP1 searches for a non-trivial factor of z using Williams' p+1 method with the starting
+// value c > 2. This finds a prime factor p of z when p+1 is b1 smooth, apart from one prime
+// which may be as large as b2Sqrt^2, provided c^2-4 is a quadratic non-residue modulo p. As that
+// is not known in advance, try several values of c. If p-1 is smooth instead then p may also be
+// found. FLINT's fmpz_factor_pp1 is used where available, otherwise only stage 1 is run using
+// LucasUV. The factor found is returned, or nil if no factor was found.
+func (z *Fmpz) FactorPP1(b1, b2Sqrt, c uint64) *Fmpz {
+	z.doinit()
+
+	n := new(Fmpz).Abs(z)
+	if n.Cmp(NewFmpz(3)) <= 0 {
+		return nil
+	}
+	if n.TstBit(0) == 0 {
+		return NewFmpz(2)
+	}
+
+	f := new(Fmpz)
+	f.doinit()
+	switch C.compat_fmpz_factor_pp1(&f.i[0], &n.i[0], C.ulong(b1), C.ulong(b2Sqrt), C.ulong(c)) {
+	case 0:
+		return nil
+	case -1:
+		f = n.pp1Stage1(b1, c)
+	}
+
+	if f == nil || f.Cmp(NewFmpz(1)) <= 0 || f.Equals(n) {
+		return nil
+	}
+
+	return f
+}
+
+// pp1Stage1 runs stage 1 of Williams' p+1 method on the odd integer z using the V sequence with
+// parameters (c, 1) and returns gcd(V - 2, z), or nil if no factor was found.
+func (z *Fmpz) pp1Stage1(b1, c uint64) *Fmpz {
+	v := new(Fmpz).SetUint64(c)
+	u := new(Fmpz)
+	one := NewFmpz(1)
+	for p := nextPrimeUI(1); p <= b1; p = nextPrimeUI(p) {
+		u.LucasUV(v, v, one, new(Fmpz).SetUint64(maxPrimePower(p, b1)), z)
+	}
+
+	g := new(Fmpz).GCD(v.SubI(2), z)
+	if g.Cmp(one) == 0 || g.Equals(z) {
+		return nil
+	}
+
+	return g
+}
@@ -0,0 +1,267 @@
+package goflint
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFactor(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		n       string
+		sign    int
+		primes  []string
+		exps    []int
+		wantStr string
+	}{
+		{
+			name:    "1960 = 2^3 * 5 * 7^2",
+			n:       "1960",
+			sign:    1,
+			primes:  []string{"2", "5", "7"},
+			exps:    []int{3, 1, 2},
+			wantStr: "2^3 * 5 * 7^2",
+		},
+		{
+			name:    "negative number",
+			n:       "-15",
+			sign:    -1,
+			primes:  []string{"3", "5"},
+			exps:    []int{1, 1},
+			wantStr: "-1 * 3 * 5",
+		},
+		{
+			name:    "one has no factors",
+			n:       "1",
+			sign:    1,
+			wantStr: "1",
+		},
+		{
+			name:    "small RSA modulus",
+			n:       "30000000001181000000000429",
+			sign:    1,
+			primes:  []string{"1000000000039", "30000000000011"},
+			exps:    []int{1, 1},
+			wantStr: "1000000000039 * 30000000000011",
+		},
+	} {
+		n, ok := new(Fmpz).SetString(tc.n, 10)
+		if !ok {
+			t.Fatalf("Factor() %s failed converting n to Fmpz: %v", tc.name, tc.n)
+		}
+
+		got := n.Factor()
+		if got.Sign() != tc.sign {
+			t.Errorf("Factor() %s sign want / got mismatch: %d / %d", tc.name, tc.sign, got.Sign())
+		}
+
+		if got.Len() != len(tc.primes) {
+			t.Fatalf("Factor() %s number of factors want / got mismatch: %d / %d", tc.name, len(tc.primes), got.Len())
+		}
+
+		exps := got.Exponents()
+		for i, p := range got.Primes() {
+			if p.String() != tc.primes[i] || exps[i] != tc.exps[i] {
+				t.Errorf("Factor() %s factor %d want / got mismatch: %s^%d / %v^%d", tc.name, i, tc.primes[i], tc.exps[i], p, exps[i])
+			}
+		}
+
+		if got.String() != tc.wantStr {
+			t.Errorf("Factor() %s String() want / got mismatch: %s / %s", tc.name, tc.wantStr, got)
+		}
+	}
+}
+
+func TestFmpzFactorAppend(t *testing.T) {
+	p, _ := new(Fmpz).SetString("1000000000039", 10)
+	fac := NewFmpzFactor().Append(NewFmpz(2), 3).Append(p, 1)
+
+	if got, want := fac.String(), "2^3 * 1000000000039"; got != want {
+		t.Errorf("Append() want / got mismatch: %s / %s", want, got)
+	}
+	if fac.Sign() != 1 || fac.Len() != 2 || fac.GetExp(0) != 3 {
+		t.Errorf("Append() unexpected factorization: sign %d, %d factors", fac.Sign(), fac.Len())
+	}
+}
+
+func TestFactorECM(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		n      string
+		b1     uint64
+		b2     uint64
+		curves int
+	}{
+		{
+			name:   "unbalanced semiprime",
+			n:      "30000000001181000000000429",
+			b1:     2000,
+			b2:     100000,
+			curves: 200,
+		},
+	} {
+		n, _ := new(Fmpz).SetString(tc.n, 10)
+		state := new(FlintRandT)
+
+		got, err := n.FactorECM(context.Background(), state, tc.b1, tc.b2, tc.curves)
+		if err != nil {
+			t.Fatalf("FactorECM() %s got error when not expected: %v", tc.name, err)
+		}
+
+		if got == nil {
+			t.Fatalf("FactorECM() %s found no factor", tc.name)
+		}
+
+		if new(Fmpz).Mod(n, got).Sign() != 0 || got.Cmp(NewFmpz(1)) <= 0 || got.Equals(n) {
+			t.Errorf("FactorECM() %s returned a trivial or non-dividing factor: %v", tc.name, got)
+		}
+	}
+}
+
+func TestFactorECMCancelled(t *testing.T) {
+	n, _ := new(Fmpz).SetString("30000000001181000000000429", 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	got, err := n.FactorECM(ctx, new(FlintRandT), 2000, 100000, 200)
+	if err != context.Canceled {
+		t.Errorf("FactorECM() want / got error mismatch: %v / %v", context.Canceled, err)
+	}
+
+	if got != nil {
+		t.Errorf("FactorECM() expected nil factor from a cancelled search but got %v", got)
+	}
+}
+
+func TestFactorECMBadBounds(t *testing.T) {
+	if _, err := NewFmpz(91).FactorECM(context.Background(), new(FlintRandT), 100, 10, 1); err == nil {
+		t.Error("FactorECM() expected error when b2 < b1 but got nil")
+	}
+}
+
+func TestFactorPollardBrent(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		n        string
+		maxTries uint64
+		maxIters uint64
+		want     []string
+	}{
+		{
+			name:     "product of two 7 digit primes",
+			n:        "1000036000099",
+			maxTries: 5,
+			maxIters: 1000000,
+			want:     []string{"1000003", "1000033"},
+		},
+		{
+			name:     "even number",
+			n:        "1000006",
+			maxTries: 1,
+			maxIters: 1,
+			want:     []string{"2"},
+		},
+	} {
+		n, _ := new(Fmpz).SetString(tc.n, 10)
+
+		got := n.FactorPollardBrent(new(FlintRandT), tc.maxTries, tc.maxIters)
+		if got == nil {
+			t.Fatalf("FactorPollardBrent() %s found no factor", tc.name)
+		}
+
+		found := false
+		for _, w := range tc.want {
+			if got.String() == w {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("FactorPollardBrent() %s want one of / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestFactorPM1(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		n    string
+		b1   uint64
+		b2   uint64
+		want string
+	}{
+		{
+			name: "p-1 is 1000 smooth",
+			n:    "47042477281810009234438290419303",
+			b1:   1000,
+			b2:   0,
+			want: "4704247728181",
+		},
+		{
+			name: "p-1 needs stage 2",
+			n:    "282816092258710055516798910384773",
+			b1:   1000,
+			b2:   50000,
+			want: "28281609225871",
+		},
+		{
+			// p-1 = 76 * 65851 and q-1 = 108 * 65981, and both large primes fall in the same
+			// stage 2 batch.
+			name: "both factors in one stage 2 batch",
+			n:    "35663073063473",
+			b1:   1000,
+			b2:   100000,
+			want: "5004677",
+		},
+		{
+			name: "bound too small",
+			n:    "47042477281810009234438290419303",
+			b1:   100,
+			b2:   100,
+			want: "<nil>",
+		},
+	} {
+		n, _ := new(Fmpz).SetString(tc.n, 10)
+
+		got := n.FactorPM1(tc.b1, tc.b2)
+		if got.String() != tc.want {
+			t.Errorf("FactorPM1() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestFactorPP1(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		n    string
+		b1   uint64
+		want string
+	}{
+		{
+			name: "p+1 is 1000 smooth",
+			n:    "1034892129070790203149324936596077",
+			b1:   1000,
+			want: "103489212907079",
+		},
+	} {
+		n, _ := new(Fmpz).SetString(tc.n, 10)
+
+		// Only some starting values give a quadratic non-residue so try a few.
+		var got, gotStage1 *Fmpz
+		for c := uint64(3); c < 100 && (got == nil || gotStage1 == nil); c++ {
+			if got == nil {
+				got = n.FactorPP1(tc.b1, 10, c)
+			}
+			if gotStage1 == nil {
+				gotStage1 = n.pp1Stage1(tc.b1, c)
+			}
+		}
+
+		if got.String() != tc.want {
+			t.Errorf("FactorPP1() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+
+		if gotStage1.String() != tc.want {
+			t.Errorf("pp1Stage1() %s want / got mismatch: %v / %v", tc.name, tc.want, gotStage1)
+		}
+	}
+}
@@ -156,6 +156,14 @@ func (m *FmpzMat) Entry(x, y int) *Fmpz {
 	return z
 }
 
+// entry returns a copy of the value at row r and column c in the matrix m.
+func (m *FmpzMat) entry(r, c int) *Fmpz {
+	z := new(Fmpz)
+	z.doinit()
+	C.fmpz_set(&z.i[0], C.fmpz_mat_entry(&m.i[0], C.slong(r), C.slong(c)))
+	return z
+}
+
 // SetPosVal sets position pos in matrix m to val and returns m.
 func (m *FmpzMat) SetPosVal(val *Fmpz, pos int) *FmpzMat {
 	val.doinit()
@@ -0,0 +1,440 @@
+package goflint
+
+/*
+#include <flint/flint.h>
+#include <flint/fmpz.h>
+#include <flint/ulong_extras.h>
+*/
+import "C"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// certSmallBits is the size below which a certificate relies on a deterministic primality
+	// test instead of a chain of witnesses. Every such number is below 2^64 and is proven prime
+	// by IsPrime, as fmpz_is_prime is exact at this size.
+	certSmallBits = 64
+	// certTrialBound is the largest prime used to trial divide n-1 while building a certificate.
+	certTrialBound = 1 << 16
+	// certECMCurves is the number of ECM curves tried for each cofactor of n-1 that survives
+	// trial division.
+	certECMCurves = 200
+)
+
+// PrimeCertificate is a proof that N is prime which can be checked with Verify without trusting
+// the code that produced it.
+//
+// A certificate with no witnesses covers an N of at most 64 bits, whose primality is checked
+// directly by a deterministic test. Otherwise it is a Pocklington-Lehmer proof: writing
+// N-1 = F*R where F is the part of N-1 made up of the primes q listed in Witnesses, N is prime
+// if F^2 > N and each witness A satisfies A^(N-1) = 1 (mod N) and gcd(A^((N-1)/q) - 1, N) = 1.
+// Each q carries its own certificate, so the proof is a tree. When F = N-1 this is a Pratt
+// certificate.
+//
+// Certificates are serialized with encoding/json or encoding/gob, for example
+// {"n":1000000007} or {"n":...,"witnesses":[{"a":3,"q":{"n":2}},...]}.
+type PrimeCertificate struct {
+	N         *Fmpz          `json:"n"`
+	Witnesses []PrimeWitness `json:"witnesses,omitempty"`
+}
+
+// PrimeWitness is a single step of a Pocklington-Lehmer proof: a base A for the prime factor
+// of N-1 proven by Q.
+type PrimeWitness struct {
+	A *Fmpz             `json:"a"`
+	Q *PrimeCertificate `json:"q"`
+}
+
+// ProvePrime returns a certificate proving that z is prime. The certificate is built from a
+// partial factorization of z-1 found by trial division and ECM, recursing on each prime factor
+// used. An error is returned if z is not prime or if z-1 could not be factored far enough to
+// give a proof; in the latter case IsPrime can still decide primality.
+func (z *Fmpz) ProvePrime() (*PrimeCertificate, error) {
+	z.doinit()
+	if z.IsPrime() != 1 {
+		return nil, fmt.Errorf("ProvePrime: %v is not prime", z)
+	}
+	n := new(Fmpz).Set(z)
+	if n.Bits() <= certSmallBits {
+		return &PrimeCertificate{N: n}, nil
+	}
+
+	nm1 := new(Fmpz).Sub(n, NewFmpz(1))
+	primes, err := certFactor(n, nm1)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := &PrimeCertificate{N: n}
+	for _, q := range primes {
+		a, err := certWitness(n, nm1, q)
+		if err != nil {
+			return nil, err
+		}
+		qc, err := q.ProvePrime()
+		if err != nil {
+			return nil, fmt.Errorf("ProvePrime: factor %v of %v-1: %v", q, n, err)
+		}
+		cert.Witnesses = append(cert.Witnesses, PrimeWitness{A: a, Q: qc})
+	}
+	return cert, nil
+}
+
+// certFactor returns distinct primes q dividing nm1 = n-1 whose contribution F to n-1 satisfies
+// F^2 > n, found by trial division followed by ECM on the remaining cofactor.
+func certFactor(n, nm1 *Fmpz) ([]*Fmpz, error) {
+	var primes []*Fmpz
+	f := NewFmpz(1)
+	r := new(Fmpz).Set(nm1)
+
+	// add divides every power of the prime q out of r and accumulates it in f.
+	add := func(q *Fmpz) {
+		primes = append(primes, q)
+		for new(Fmpz).Mod(r, q).IsZero() {
+			r.Quo(r, q)
+			f.MulZ(q)
+		}
+	}
+	enough := func() bool {
+		return new(Fmpz).Mul(f, f).Cmp(n) > 0
+	}
+
+	for p := nextPrimeUI(1); p <= certTrialBound && !enough(); p = nextPrimeUI(p) {
+		if C.fmpz_fdiv_ui(&r.i[0], C.ulong(p)) == 0 {
+			add(new(Fmpz).SetUint64(p))
+		}
+	}
+
+	state := new(FlintRandT)
+	for !enough() && r.Cmp(NewFmpz(1)) > 0 {
+		if r.IsProbabPrime() == 1 {
+			add(new(Fmpz).Set(r))
+			continue
+		}
+		d, _ := r.FactorECM(context.Background(), state, 2000, 200000, certECMCurves)
+		if d == nil || d.Equals(r) {
+			break
+		}
+		for _, q := range d.Factor().Primes() {
+			add(q)
+		}
+	}
+
+	if !enough() {
+		return nil, fmt.Errorf("ProvePrime: could not factor enough of %v-1 to prove primality", n)
+	}
+	return primes, nil
+}
+
+// certWitness returns the smallest base a >= 2 satisfying the Pocklington condition for the
+// prime q dividing nm1 = n-1.
+func certWitness(n, nm1, q *Fmpz) (*Fmpz, error) {
+	e := new(Fmpz).Quo(nm1, q)
+	t := new(Fmpz)
+	for a := NewFmpz(2); a.Cmp(n) < 0; a.AddI(1) {
+		if t.Exp(a, nm1, n).Cmp(NewFmpz(1)) != 0 {
+			return nil, fmt.Errorf("ProvePrime: %v fails the Fermat test to base %v", n, a)
+		}
+		t.Exp(a, e, n).SubI(1)
+		if t.GCD(t, n).Cmp(NewFmpz(1)) == 0 {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("ProvePrime: no witness for factor %v of %v-1", q, n)
+}
+
+// Verify checks the certificate and returns nil if it proves that N is prime, otherwise it
+// returns an error describing the first step that failed.
+func (c *PrimeCertificate) Verify() error {
+	if c == nil || c.N == nil {
+		return errors.New("Verify: empty certificate")
+	}
+	n := c.N
+	if n.Cmp(NewFmpz(2)) < 0 {
+		return fmt.Errorf("Verify: %v is less than 2", n)
+	}
+
+	if len(c.Witnesses) == 0 {
+		if n.Bits() > certSmallBits {
+			return fmt.Errorf("Verify: %v has no witnesses but exceeds %d bits", n, certSmallBits)
+		}
+		if n.IsPrime() != 1 {
+			return fmt.Errorf("Verify: %v is not prime", n)
+		}
+		return nil
+	}
+
+	nm1 := new(Fmpz).Sub(n, NewFmpz(1))
+	f := NewFmpz(1)
+	r := new(Fmpz).Set(nm1)
+	t := new(Fmpz)
+	for _, w := range c.Witnesses {
+		if w.A == nil || w.Q == nil || w.Q.N == nil {
+			return fmt.Errorf("Verify: incomplete witness for %v", n)
+		}
+		q := w.Q.N
+		if q.Cmp(NewFmpz(2)) < 0 || !t.Mod(r, q).IsZero() {
+			return fmt.Errorf("Verify: %v does not divide the unfactored part of %v-1", q, n)
+		}
+		if err := w.Q.Verify(); err != nil {
+			return err
+		}
+		for t.Mod(r, q).IsZero() {
+			r.Quo(r, q)
+			f.MulZ(q)
+		}
+
+		if w.A.Cmp(NewFmpz(2)) < 0 || w.A.Cmp(n) >= 0 {
+			return fmt.Errorf("Verify: base %v out of range for %v", w.A, n)
+		}
+		if t.Exp(w.A, nm1, n).Cmp(NewFmpz(1)) != 0 {
+			return fmt.Errorf("Verify: %v^(n-1) != 1 mod %v", w.A, n)
+		}
+		t.Exp(w.A, new(Fmpz).Quo(nm1, q), n).SubI(1)
+		if t.GCD(t, n).Cmp(NewFmpz(1)) != 0 {
+			return fmt.Errorf("Verify: gcd(%v^((n-1)/%v) - 1, %v) != 1", w.A, q, n)
+		}
+	}
+
+	if t.Mul(f, f).Cmp(n) <= 0 {
+		return fmt.Errorf("Verify: factored part of %v-1 is too small", n)
+	}
+	return nil
+}
+
+// Method returns the kind of proof held by the certificate: "small" when N is checked directly,
+// "Pratt" when N-1 is completely factored and "Pocklington" otherwise.
+func (c *PrimeCertificate) Method() string {
+	if len(c.Witnesses) == 0 {
+		return "small"
+	}
+	r := new(Fmpz).Sub(c.N, NewFmpz(1))
+	for _, w := range c.Witnesses {
+		if w.Q == nil || w.Q.N == nil || w.Q.N.Cmp(NewFmpz(2)) < 0 {
+			continue
+		}
+		for new(Fmpz).Mod(r, w.Q.N).IsZero() {
+			r.Quo(r, w.Q.N)
+		}
+	}
+	if r.Cmp(NewFmpz(1)) == 0 {
+		return "Pratt"
+	}
+	return "Pocklington"
+}
+
+// String returns a readable outline of the certificate with one line per prime, indented to
+// show the proof tree, e.g. "p = 23 (small)".
+func (c *PrimeCertificate) String() string {
+	var b strings.Builder
+	c.write(&b, 0)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// write appends the outline of c to b at the given depth.
+func (c *PrimeCertificate) write(b *strings.Builder, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	fmt.Fprintf(b, "p = %v (%s)\n", c.N, c.Method())
+	for _, w := range c.Witnesses {
+		b.WriteString(strings.Repeat("  ", depth+1))
+		if w.Q == nil {
+			fmt.Fprintf(b, "a = %v for q = <nil>\n", w.A)
+			continue
+		}
+		fmt.Fprintf(b, "a = %v for q = %v\n", w.A, w.Q.N)
+		w.Q.write(b, depth+2)
+	}
+}
+
+// Prime generation.
+
+// primeSieveBound is the largest prime used to discard candidates by trial division before
+// running a probable prime test when generating constrained primes.
+const primeSieveBound = 1000
+
+// NextPrime sets z to the smallest probable prime greater than x and returns z. The result has
+// passed a Baillie-PSW test, which has no known counterexamples; use IsPrime or ProvePrime when a
+// proof is needed.
+func (z *Fmpz) NextPrime(x *Fmpz) *Fmpz {
+	x.doinit()
+	z.doinit()
+	C.fmpz_nextprime(&z.i[0], &x.i[0], 0)
+	return z
+}
+
+// PrevPrime sets z to the largest probable prime less than x and returns z. If x <= 2 there is
+// no such prime, z is left unchanged and nil is returned.
+func (z *Fmpz) PrevPrime(x *Fmpz) *Fmpz {
+	x.doinit()
+	z.doinit()
+	if x.Cmp(NewFmpz(2)) <= 0 {
+		return nil
+	}
+	if x.Cmp(NewFmpz(3)) == 0 {
+		return z.SetInt64(2)
+	}
+
+	c := new(Fmpz).Sub(x, NewFmpz(1))
+	if c.TstBit(0) == 0 {
+		c.SubI(1)
+	}
+	for ; c.IsProbabPrime() != 1; c.SubI(2) {
+	}
+	return z.Set(c)
+}
+
+// RandPrime sets z to a random probable prime with exactly bits bits and returns z. The bits
+// must be at least 2 otherwise a run-time panic occurs.
+func (z *Fmpz) RandPrime(state *FlintRandT, bits int) *Fmpz {
+	if bits < 2 {
+		panic("RandPrime: bits must be at least 2")
+	}
+	z.doinit()
+	state.flintRandTDoinit()
+	C.fmpz_randprime(&z.i[0], &state.i[0], C.flint_bitcnt_t(bits), 0)
+	return z
+}
+
+// randExact sets z to a random integer with exactly bits bits and returns z.
+func (z *Fmpz) randExact(state *FlintRandT, bits int) *Fmpz {
+	// z must be initialised before FLINT writes to it, otherwise the draw is lost to the lazy
+	// fmpz_init in Abs.
+	z.doinit()
+	C.fmpz_randbits(&z.i[0], &state.i[0], C.flint_bitcnt_t(bits))
+	return z.Abs(z)
+}
+
+// randOdd sets z to a random odd integer with exactly bits bits and returns z.
+func (z *Fmpz) randOdd(state *FlintRandT, bits int) *Fmpz {
+	return z.randExact(state, bits).SetBit(0)
+}
+
+// hasSmallFactor reports whether z is divisible by an odd prime up to primeSieveBound other
+// than z itself.
+func (z *Fmpz) hasSmallFactor() bool {
+	for p := uint64(3); p <= primeSieveBound; p = nextPrimeUI(p) {
+		if C.fmpz_fdiv_ui(&z.i[0], C.ulong(p)) == 0 && C.fmpz_cmp_ui(&z.i[0], C.ulong(p)) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// RandSafePrime sets z to a random safe prime with exactly bits bits, that is a probable prime
+// p = 2q+1 where q is also a probable prime, and returns z. The bits must be at least 3
+// otherwise a run-time panic occurs.
+func (z *Fmpz) RandSafePrime(state *FlintRandT, bits int) *Fmpz {
+	if bits < 3 {
+		panic("RandSafePrime: bits must be at least 3")
+	}
+	z.doinit()
+	state.flintRandTDoinit()
+
+	q := new(Fmpz)
+	p := new(Fmpz)
+	for {
+		if bits == 3 {
+			// 5 = 2*2+1 is the only safe prime with an even q.
+			q.SetInt64(int64(2 + C.n_randint(&state.i[0], 2)))
+		} else {
+			q.randOdd(state, bits-1)
+		}
+		p.Set(q).Lsh(1).AddI(1)
+		if q.hasSmallFactor() || p.hasSmallFactor() {
+			continue
+		}
+		if q.IsProbabPrime() == 1 && p.IsProbabPrime() == 1 {
+			return z.Set(p)
+		}
+	}
+}
+
+// RandPrimeCongruent sets z to a random probable prime p with exactly bits bits such that
+// p = a (mod m) and returns z. An error is returned if a and m are not coprime, if m does not
+// leave room for such a prime below 2^bits, or if no prime is found after a bounded number of
+// attempts.
+func (z *Fmpz) RandPrimeCongruent(state *FlintRandT, bits int, a, m *Fmpz) (*Fmpz, error) {
+	a.doinit()
+	m.doinit()
+	z.doinit()
+	state.flintRandTDoinit()
+	if m.Sign() <= 0 {
+		return nil, errors.New("RandPrimeCongruent: modulus must be positive")
+	}
+	if m.Bits() >= bits {
+		return nil, fmt.Errorf("RandPrimeCongruent: modulus %v is too large for a %d bit prime", m, bits)
+	}
+	if new(Fmpz).GCD(a, m).Cmp(NewFmpz(1)) != 0 {
+		return nil, fmt.Errorf("RandPrimeCongruent: %v and %v are not coprime", a, m)
+	}
+
+	r := new(Fmpz).Mod(a, m)
+	p := new(Fmpz)
+	t := new(Fmpz)
+	for attempt := 0; attempt < 100*bits+1000; attempt++ {
+		// Round a random bits bit value down to the residue class, stepping up once if that
+		// falls out of range.
+		p.randExact(state, bits)
+		p.Sub(p, t.Mod(t.Sub(p, r), m))
+		if p.Bits() != bits {
+			p.Add(p, m)
+		}
+		if p.Bits() != bits || p.hasSmallFactor() {
+			continue
+		}
+		if p.IsProbabPrime() == 1 {
+			return z.Set(p), nil
+		}
+	}
+	return nil, fmt.Errorf("RandPrimeCongruent: no %d bit prime found congruent to %v mod %v", bits, a, m)
+}
+
+// RandPrimeWithPM1Factor sets z to a random probable prime p with exactly bits bits such that f
+// divides p-1 and returns z. It is RandPrimeCongruent with a = 1 and m = f (or 2f if f is odd)
+// and fails in the same cases.
+func (z *Fmpz) RandPrimeWithPM1Factor(state *FlintRandT, bits int, f *Fmpz) (*Fmpz, error) {
+	m := new(Fmpz).Set(f)
+	if m.TstBit(0) == 1 {
+		m.Lsh(1)
+	}
+	return z.RandPrimeCongruent(state, bits, NewFmpz(1), m)
+}
+
+// RandPrimePM1Smooth sets z to a random probable prime p with exactly bits bits such that p-1 is
+// b smooth, that is every prime factor of p-1 is at most b, and returns z. Such primes are weak
+// against Pollard's p-1 method. The bits must be at least 3 and b at least 2 otherwise a
+// run-time panic occurs.
+func (z *Fmpz) RandPrimePM1Smooth(state *FlintRandT, bits int, b uint64) *Fmpz {
+	if bits < 3 || b < 2 {
+		panic("RandPrimePM1Smooth: bits must be at least 3 and b at least 2")
+	}
+	z.doinit()
+	state.flintRandTDoinit()
+
+	m := new(Fmpz)
+	for {
+		// Build p-1 from 2 and random primes up to b, choosing smaller primes as the target
+		// size gets close so that the bit length is hit exactly.
+		m.SetInt64(2)
+		for m.Bits() < bits {
+			limit := b
+			if need := bits - m.Bits(); need < 64 && uint64(1)<<uint(need) < limit {
+				limit = uint64(1) << uint(need)
+			}
+			q := uint64(2)
+			if limit > 2 {
+				u := 2 + uint64(C.n_randint(&state.i[0], C.ulong(limit-1)))
+				q = uint64(C.n_prevprime(C.ulong(u+1), 1))
+			}
+			C.fmpz_mul_ui(&m.i[0], &m.i[0], C.ulong(q))
+		}
+		if m.AddI(1).IsProbabPrime() == 1 {
+			return z.Set(m)
+		}
+	}
+}
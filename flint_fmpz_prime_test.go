@@ -0,0 +1,325 @@
+package goflint
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestProvePrime(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		n      string
+		method string
+	}{
+		{
+			name:   "word sized prime",
+			n:      "18446744073709551557",
+			method: "small",
+		},
+		{
+			name:   "mersenne prime 2^89-1",
+			n:      "618970019642690137449562111",
+			method: "Pocklington",
+		},
+		{
+			name: "mersenne prime 2^127-1",
+			n:    "170141183460469231731687303715884105727",
+		},
+	} {
+		n, _ := new(Fmpz).SetString(tc.n, 10)
+
+		cert, err := n.ProvePrime()
+		if err != nil {
+			t.Fatalf("ProvePrime() %s got error when not expected: %v", tc.name, err)
+		}
+
+		if err := cert.Verify(); err != nil {
+			t.Errorf("Verify() %s got error when not expected: %v", tc.name, err)
+		}
+
+		if !cert.N.Equals(n) {
+			t.Errorf("ProvePrime() %s N want / got mismatch: %v / %v", tc.name, n, cert.N)
+		}
+
+		if tc.method != "" && cert.Method() != tc.method {
+			t.Errorf("Method() %s want / got mismatch: %s / %s", tc.name, tc.method, cert.Method())
+		}
+	}
+}
+
+func TestProvePrimeComposite(t *testing.T) {
+	n, _ := new(Fmpz).SetString("30000000001181000000000429", 10)
+	if _, err := n.ProvePrime(); err == nil {
+		t.Error("ProvePrime() expected error for a composite but got nil")
+	}
+}
+
+func TestPrimeCertificateJSON(t *testing.T) {
+	n, _ := new(Fmpz).SetString("170141183460469231731687303715884105727", 10)
+	cert, err := n.ProvePrime()
+	if err != nil {
+		t.Fatalf("ProvePrime() got error when not expected: %v", err)
+	}
+
+	js, err := json.Marshal(cert)
+	if err != nil {
+		t.Fatalf("json.Marshal() got error when not expected: %v", err)
+	}
+
+	got := new(PrimeCertificate)
+	if err := json.Unmarshal(js, got); err != nil {
+		t.Fatalf("json.Unmarshal() got error when not expected: %v", err)
+	}
+
+	if got.String() != cert.String() {
+		t.Errorf("json round trip want / got mismatch: %s / %s", cert, got)
+	}
+
+	if err := got.Verify(); err != nil {
+		t.Errorf("Verify() of decoded certificate got error when not expected: %v", err)
+	}
+}
+
+func TestPrimeCertificateVerifyRejects(t *testing.T) {
+	n, _ := new(Fmpz).SetString("618970019642690137449562111", 10)
+
+	for _, tc := range []struct {
+		name   string
+		tamper func(c *PrimeCertificate)
+	}{
+		{
+			// The first witness is for q = 2, which a square can never satisfy.
+			name:   "square base for q = 2",
+			tamper: func(c *PrimeCertificate) { c.Witnesses[0].A = NewFmpz(4) },
+		},
+		{
+			name:   "missing witnesses",
+			tamper: func(c *PrimeCertificate) { c.Witnesses = c.Witnesses[:1] },
+		},
+		{
+			name:   "no witnesses for a large number",
+			tamper: func(c *PrimeCertificate) { c.Witnesses = nil },
+		},
+		{
+			name:   "factor below two",
+			tamper: func(c *PrimeCertificate) { c.Witnesses[0].Q = &PrimeCertificate{N: NewFmpz(1)} },
+		},
+		{
+			name: "composite number",
+			tamper: func(c *PrimeCertificate) {
+				c.N, _ = new(Fmpz).SetString("30000000001181000000000429", 10)
+			},
+		},
+	} {
+		cert, err := n.ProvePrime()
+		if err != nil {
+			t.Fatalf("ProvePrime() %s got error when not expected: %v", tc.name, err)
+		}
+
+		tc.tamper(cert)
+		if err := cert.Verify(); err == nil {
+			t.Errorf("Verify() %s expected error but got nil", tc.name)
+		}
+	}
+}
+
+func TestNextPrime(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		x    string
+		want string
+	}{
+		{
+			name: "next prime after 100",
+			x:    "100",
+			want: "101",
+		},
+		{
+			name: "next prime after a prime",
+			x:    "101",
+			want: "103",
+		},
+		{
+			name: "next prime after 2^64",
+			x:    "18446744073709551616",
+			want: "18446744073709551629",
+		},
+	} {
+		x, _ := new(Fmpz).SetString(tc.x, 10)
+		if got := new(Fmpz).NextPrime(x); got.String() != tc.want {
+			t.Errorf("NextPrime() %s want / got mismatch: %s / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestPrevPrime(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		x    string
+		want string
+	}{
+		{
+			name: "previous prime before 100",
+			x:    "100",
+			want: "97",
+		},
+		{
+			name: "previous prime before 3",
+			x:    "3",
+			want: "2",
+		},
+		{
+			name: "no prime before 2",
+			x:    "2",
+			want: "<nil>",
+		},
+		{
+			name: "previous prime before 2^64",
+			x:    "18446744073709551616",
+			want: "18446744073709551557",
+		},
+	} {
+		x, _ := new(Fmpz).SetString(tc.x, 10)
+		if got := new(Fmpz).PrevPrime(x); got.String() != tc.want {
+			t.Errorf("PrevPrime() %s want / got mismatch: %s / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestRandPrime(t *testing.T) {
+	state := new(FlintRandT)
+	for _, bits := range []int{2, 17, 64, 256} {
+		got := new(Fmpz).RandPrime(state, bits)
+		if got.Bits() != bits || got.IsProbabPrime() != 1 {
+			t.Errorf("RandPrime() %d bits returned %v with %d bits", bits, got, got.Bits())
+		}
+	}
+}
+
+func TestRandOdd(t *testing.T) {
+	for _, bits := range []int{2, 17, 62, 63, 100} {
+		// The first draw from a fresh state must land in an uninitialised Fmpz just as it does in
+		// an initialised one.
+		got := new(Fmpz).randOdd(NewFlintRandT(7), bits)
+		want := NewFmpz(1).randOdd(NewFlintRandT(7), bits)
+		if !got.Equals(want) {
+			t.Errorf("randOdd() %d bits first draw want / got mismatch: %v / %v", bits, want, got)
+		}
+		if got.Bits() != bits || got.TstBit(0) != 1 {
+			t.Errorf("randOdd() %d bits returned %v which is not an odd %d bit integer", bits, got, bits)
+		}
+	}
+}
+
+func TestRandSafePrime(t *testing.T) {
+	state := new(FlintRandT)
+	for _, bits := range []int{3, 16, 128} {
+		got := new(Fmpz).RandSafePrime(state, bits)
+		q := new(Fmpz).Set(got).Rsh(1)
+		if got.Bits() != bits || got.IsProbabPrime() != 1 || q.IsProbabPrime() != 1 {
+			t.Errorf("RandSafePrime() %d bits returned %v which is not a %d bit safe prime", bits, got, bits)
+		}
+	}
+}
+
+func TestRandPrimeCongruent(t *testing.T) {
+	state := new(FlintRandT)
+	for _, tc := range []struct {
+		name    string
+		bits    int
+		a       int64
+		m       int64
+		wantErr bool
+	}{
+		{
+			name: "p = 3 mod 1000",
+			bits: 128,
+			a:    3,
+			m:    1000,
+		},
+		{
+			name: "p = 3 mod 4",
+			bits: 20,
+			a:    3,
+			m:    4,
+		},
+		{
+			name:    "a and m not coprime",
+			bits:    64,
+			a:       4,
+			m:       6,
+			wantErr: true,
+		},
+		{
+			name:    "modulus too large",
+			bits:    8,
+			a:       1,
+			m:       1000,
+			wantErr: true,
+		},
+	} {
+		got, err := new(Fmpz).RandPrimeCongruent(state, tc.bits, NewFmpz(tc.a), NewFmpz(tc.m))
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("RandPrimeCongruent() %s expected error but got %v", tc.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("RandPrimeCongruent() %s got error when not expected: %v", tc.name, err)
+		}
+		if got.Bits() != tc.bits || got.IsProbabPrime() != 1 || new(Fmpz).Mod(got, NewFmpz(tc.m)).Int64() != tc.a {
+			t.Errorf("RandPrimeCongruent() %s returned %v which does not meet the constraints", tc.name, got)
+		}
+	}
+
+	// Both 8 bit integers congruent to 35 mod 64, 163 and 227, are prime, so the first draw
+	// decides the result: 227 if it is at least 227 and 163 otherwise.
+	got, err := new(Fmpz).RandPrimeCongruent(NewFlintRandT(7), 8, NewFmpz(35), NewFmpz(64))
+	if err != nil {
+		t.Fatalf("RandPrimeCongruent() first draw got error when not expected: %v", err)
+	}
+	want := NewFmpz(163)
+	if new(Fmpz).randExact(NewFlintRandT(7), 8).Cmp(NewFmpz(227)) >= 0 {
+		want.SetInt64(227)
+	}
+	if !got.Equals(want) {
+		t.Errorf("RandPrimeCongruent() first draw want / got mismatch: %v / %v", want, got)
+	}
+}
+
+func TestRandPrimeWithPM1Factor(t *testing.T) {
+	// 3^5 * 7 * 11^2 * 13
+	f := NewFmpz(2675673)
+	got, err := new(Fmpz).RandPrimeWithPM1Factor(new(FlintRandT), 96, f)
+	if err != nil {
+		t.Fatalf("RandPrimeWithPM1Factor() got error when not expected: %v", err)
+	}
+	pm1 := new(Fmpz).Sub(got, NewFmpz(1))
+	if got.Bits() != 96 || got.IsProbabPrime() != 1 || !new(Fmpz).Mod(pm1, f).IsZero() {
+		t.Errorf("RandPrimeWithPM1Factor() returned %v which does not meet the constraints", got)
+	}
+}
+
+func TestRandPrimePM1Smooth(t *testing.T) {
+	state := new(FlintRandT)
+	for _, tc := range []struct {
+		bits int
+		b    uint64
+	}{
+		{bits: 3, b: 2},
+		{bits: 128, b: 1000},
+		{bits: 256, b: 100000},
+	} {
+		got := new(Fmpz).RandPrimePM1Smooth(state, tc.bits, tc.b)
+		if got.Bits() != tc.bits || got.IsProbabPrime() != 1 {
+			t.Errorf("RandPrimePM1Smooth() %d bits returned %v", tc.bits, got)
+			continue
+		}
+		for _, q := range new(Fmpz).Sub(got, NewFmpz(1)).Factor().Primes() {
+			if q.Cmp(new(Fmpz).SetUint64(tc.b)) > 0 {
+				t.Errorf("RandPrimePM1Smooth() %d bits returned %v with factor %v of p-1 above %d", tc.bits, got, q, tc.b)
+			}
+		}
+	}
+}
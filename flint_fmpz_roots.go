@@ -0,0 +1,472 @@
+package goflint
+
+/*
+#include <flint/flint.h>
+#include <flint/fmpz.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SqrtMod sets z to a square root of a modulo the prime p and returns z and true. If a is not a
+// square modulo p, z is left unchanged and false is returned. The result is undefined if p is not
+// prime.
+func (z *Fmpz) SqrtMod(a, p *Fmpz) (*Fmpz, bool) {
+	z.doinit()
+	a.doinit()
+	p.doinit()
+
+	r := new(Fmpz)
+	r.doinit()
+	if C.fmpz_sqrtmod(&r.i[0], &a.i[0], &p.i[0]) == 0 {
+		return z, false
+	}
+	return z.Set(r), true
+}
+
+// SqrtModPrimePower returns every square root of z modulo p^k, where p is prime and k >= 1, in
+// increasing order. Roots modulo p are lifted to p^k by Hensel lifting. The result is empty if z
+// is not a square modulo p^k. When p divides z there may be many roots, up to 2*p^(k/2).
+func (z *Fmpz) SqrtModPrimePower(p *Fmpz, k int) []*Fmpz {
+	if k < 1 {
+		panic("SqrtModPrimePower: exponent must be positive")
+	}
+	z.doinit()
+	p.doinit()
+
+	m := new(Fmpz).ExpXI(p, k)
+	a := new(Fmpz).Mod(z, m)
+
+	// Write a = p^(2h) * u with u a unit. A root x then has the form p^h * y where y is free modulo
+	// p^(k-h) apart from y^2 = u (mod p^j) with j = k - 2h. For a = 0 take h = ceil(k/2), j = 0.
+	h, j := (k+1)/2, 0
+	u := new(Fmpz).Set(a)
+	units := []*Fmpz{NewFmpz(0)}
+	if !a.IsZero() {
+		v := 0
+		for rem := new(Fmpz); rem.Mod(u, p).IsZero(); v++ {
+			u.Quo(u, p)
+		}
+		if v%2 != 0 {
+			return nil
+		}
+		h, j = v/2, k-v
+		if units = sqrtModUnit(u, p, j); len(units) == 0 {
+			return nil
+		}
+	}
+
+	// Each root y modulo p^j gives p^(k-h-j) roots p^h * (y + t*p^j) modulo p^k.
+	ph := new(Fmpz).ExpXI(p, h)
+	step := new(Fmpz).Mul(ph, new(Fmpz).ExpXI(p, j))
+	count := new(Fmpz).ExpXI(p, k-h-j)
+	var roots []*Fmpz
+	for _, y := range units {
+		x := new(Fmpz).Mul(y, ph)
+		for t := new(Fmpz); t.Cmp(count) < 0; t.AddI(1) {
+			roots = append(roots, new(Fmpz).Set(x))
+			x.Add(x, step)
+		}
+	}
+	sortFmpz(roots)
+	return roots
+}
+
+// sqrtModUnit returns the square roots of the unit u modulo p^j for the prime p, or nil if there
+// are none.
+func sqrtModUnit(u, p *Fmpz, j int) []*Fmpz {
+	if j == 0 {
+		return []*Fmpz{NewFmpz(0)}
+	}
+	m := new(Fmpz).ExpXI(p, j)
+	u = new(Fmpz).Mod(u, m)
+
+	if p.Cmp(NewFmpz(2)) == 0 {
+		return sqrtModUnit2(u, j)
+	}
+
+	r, ok := new(Fmpz).SqrtMod(u, p)
+	if !ok {
+		return nil
+	}
+
+	// Newton iteration r -= (r^2 - u) / 2r doubles the precision of the root at each step.
+	d := new(Fmpz)
+	inv := new(Fmpz)
+	for !d.Mul(r, r).SubZ(u).ModZ(m).IsZero() {
+		inv.ModInverse(new(Fmpz).Add(r, r), m)
+		r.Sub(r, d.MulZ(inv)).ModZ(m)
+	}
+	return []*Fmpz{r, new(Fmpz).Sub(m, r)}
+}
+
+// sqrtModUnit2 returns the square roots of the odd integer 0 < u < 2^j modulo 2^j, or nil if there
+// are none.
+func sqrtModUnit2(u *Fmpz, j int) []*Fmpz {
+	switch {
+	case j == 1:
+		return []*Fmpz{NewFmpz(1)}
+	case j == 2:
+		if u.Uint64()&3 != 1 {
+			return nil
+		}
+		return []*Fmpz{NewFmpz(1), NewFmpz(3)}
+	case u.Uint64()&7 != 1:
+		return nil
+	}
+
+	// With r^2 = u (mod 2^i) and i >= 3, either r or r + 2^(i-1) is a root modulo 2^(i+1).
+	r := NewFmpz(1)
+	d := new(Fmpz)
+	for i := 3; i < j; i++ {
+		d.Mul(r, r).SubZ(u)
+		if d.TstBit(i) != 0 {
+			r.Add(r, new(Fmpz).SetBit(i-1))
+		}
+	}
+
+	m := new(Fmpz).SetBit(j)
+	half := new(Fmpz).SetBit(j - 1)
+	return []*Fmpz{
+		r,
+		new(Fmpz).Sub(m, r),
+		new(Fmpz).Add(r, half).ModZ(m),
+		new(Fmpz).Sub(m, r).AddZ(half).ModZ(m),
+	}
+}
+
+// SqrtModFactored returns every square root of z modulo n in increasing order, where fac is the
+// factorization of n, for example as built with Append. The roots modulo each prime power are
+// found with SqrtModPrimePower and combined by the Chinese remainder theorem, so a modulus with
+// r distinct odd prime factors coprime to z has 2^r roots. The result is empty if z is not a
+// square modulo n, and an error is returned if n is not positive.
+func (z *Fmpz) SqrtModFactored(fac *FmpzFactor) ([]*Fmpz, error) {
+	moduli, err := factorPrimePowers("SqrtModFactored", fac)
+	if err != nil {
+		return nil, err
+	}
+
+	var perPrime [][]*Fmpz
+	for i, p := range fac.Primes() {
+		roots := z.SqrtModPrimePower(p, fac.GetExp(i))
+		if len(roots) == 0 {
+			return nil, nil
+		}
+		perPrime = append(perPrime, roots)
+	}
+	return crtCombineAll(moduli, perPrime)
+}
+
+// factorPrimePowers returns the prime powers p^e of the factorization fac, or an error if the
+// factored integer is not positive.
+func factorPrimePowers(fn string, fac *FmpzFactor) ([]*Fmpz, error) {
+	if fac.Sign() <= 0 {
+		return nil, fmt.Errorf("%s: modulus %v is not positive", fn, fac)
+	}
+	var moduli []*Fmpz
+	for i, p := range fac.Primes() {
+		moduli = append(moduli, new(Fmpz).ExpXI(p, fac.GetExp(i)))
+	}
+	return moduli, nil
+}
+
+// crtCombineAll returns, in increasing order, every value modulo the product of moduli which is
+// congruent modulo moduli[i] to one of the residues in residues[i] for each i. A modulus of 1 is
+// represented by an empty list of moduli, which gives the single value 0. Each residues[i] must
+// be non-empty.
+func crtCombineAll(moduli []*Fmpz, residues [][]*Fmpz) ([]*Fmpz, error) {
+	if len(moduli) == 0 {
+		return []*Fmpz{NewFmpz(0)}, nil
+	}
+	ctx, err := NewCRTContext(moduli)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*Fmpz
+	idx := make([]int, len(moduli))
+	pick := make([]*Fmpz, len(moduli))
+	for {
+		for i, j := range idx {
+			pick[i] = residues[i][j]
+		}
+		x, err := ctx.CRT(new(Fmpz), pick)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, x)
+
+		// Step to the next combination, odometer style.
+		i := 0
+		for ; i < len(idx); i++ {
+			if idx[i]++; idx[i] < len(residues[i]) {
+				break
+			}
+			idx[i] = 0
+		}
+		if i == len(idx) {
+			break
+		}
+	}
+	sortFmpz(out)
+	return out, nil
+}
+
+// sortFmpz sorts xs into increasing order.
+func sortFmpz(xs []*Fmpz) {
+	sort.Slice(xs, func(i, j int) bool { return xs[i].Cmp(xs[j]) < 0 })
+}
+
+// RootModPrimePower returns every e-th root of z modulo p^k, where p is prime, e >= 1 and k >= 1,
+// in increasing order. Unlike inverting e modulo p-1, this handles gcd(e, p-1) > 1: the roots
+// modulo p are found with the Adleman-Manders-Miller algorithm and then lifted to p^k. The result
+// is empty if z is not an e-th power modulo p^k.
+//
+// There are gcd(e, p-1) roots of a unit modulo p, and when p divides z or e each of them may lift
+// to many roots modulo p^k. The Adleman-Manders-Miller step takes time proportional to the
+// largest prime r dividing e with r^2 dividing p-1, so it is only practical when that is small.
+func (z *Fmpz) RootModPrimePower(e, p *Fmpz, k int) []*Fmpz {
+	if k < 1 {
+		panic("RootModPrimePower: exponent must be positive")
+	}
+	if e.Sign() <= 0 {
+		panic("RootModPrimePower: root must be positive")
+	}
+	z.doinit()
+	p.doinit()
+
+	m := new(Fmpz).ExpXI(p, k)
+	a := new(Fmpz).Mod(z, m)
+
+	// As for SqrtModPrimePower write a = p^(eh) * u with u a unit, so a root is p^h * y with y^e = u
+	// (mod p^j) for j = k - eh, and y free modulo p^(k-h). For a = 0 take h = ceil(k/e), j = 0.
+	h, j := 1, 0
+	if e.Cmp(NewFmpz(int64(k))) < 0 {
+		ei := int(e.Int64())
+		h = (k + ei - 1) / ei
+	}
+	u := new(Fmpz).Set(a)
+	units := []*Fmpz{NewFmpz(0)}
+	if !a.IsZero() {
+		v := 0
+		for rem := new(Fmpz); rem.Mod(u, p).IsZero(); v++ {
+			u.Quo(u, p)
+		}
+		h = 0
+		if v > 0 {
+			if e.Cmp(NewFmpz(int64(v))) > 0 || v%int(e.Int64()) != 0 {
+				return nil
+			}
+			h = v / int(e.Int64())
+		}
+		j = k - v
+		if units = rootModUnit(u, e, p, j); len(units) == 0 {
+			return nil
+		}
+	}
+
+	ph := new(Fmpz).ExpXI(p, h)
+	step := new(Fmpz).Mul(ph, new(Fmpz).ExpXI(p, j))
+	count := new(Fmpz).ExpXI(p, k-h-j)
+	var roots []*Fmpz
+	for _, y := range units {
+		x := new(Fmpz).Mul(y, ph)
+		for t := new(Fmpz); t.Cmp(count) < 0; t.AddI(1) {
+			roots = append(roots, new(Fmpz).Set(x))
+			x.Add(x, step)
+		}
+	}
+	sortFmpz(roots)
+	return roots
+}
+
+// rootModUnit returns the e-th roots of the unit u modulo p^j for the prime p, or nil if there are
+// none.
+func rootModUnit(u, e, p *Fmpz, j int) []*Fmpz {
+	if j == 0 {
+		return []*Fmpz{NewFmpz(0)}
+	}
+	roots := rootModPrime(u, e, p)
+	if j == 1 || len(roots) == 0 {
+		return roots
+	}
+
+	m := new(Fmpz).ExpXI(p, j)
+	u = new(Fmpz).Mod(u, m)
+	d := new(Fmpz)
+	if !new(Fmpz).Mod(e, p).IsZero() {
+		// Each root lifts uniquely by the Newton iteration x -= (x^e - u) / (e*x^(e-1)).
+		em1 := new(Fmpz).Sub(e, NewFmpz(1))
+		inv := new(Fmpz)
+		for _, x := range roots {
+			for !d.Exp(x, e, m).SubZ(u).ModZ(m).IsZero() {
+				inv.Exp(x, em1, m).MulZ(e).ModZ(m)
+				inv.ModInverse(inv, m)
+				x.Sub(x, d.MulZ(inv)).ModZ(m)
+			}
+		}
+		return roots
+	}
+
+	// When p divides e a root may have several lifts or none, so try each of the p candidates
+	// x + t*p^i for every root x modulo p^i. This is cheap as p <= e.
+	pi := new(Fmpz).Set(p)
+	next := new(Fmpz)
+	for i := 1; i < j; i++ {
+		next.Mul(pi, p)
+		un := new(Fmpz).Mod(u, next)
+		var lifted []*Fmpz
+		for _, x := range roots {
+			y := new(Fmpz).Set(x)
+			for t := new(Fmpz); t.Cmp(p) < 0; t.AddI(1) {
+				if d.Exp(y, e, next).Equals(un) {
+					lifted = append(lifted, new(Fmpz).Set(y))
+				}
+				y.Add(y, pi)
+			}
+		}
+		if roots = lifted; len(roots) == 0 {
+			return nil
+		}
+		pi.Set(next)
+	}
+	return roots
+}
+
+// rootModPrime returns the e-th roots of the unit u modulo the prime p, or nil if there are none.
+func rootModPrime(u, e, p *Fmpz) []*Fmpz {
+	one := NewFmpz(1)
+	pm1 := new(Fmpz).Sub(p, one)
+	g := new(Fmpz).GCD(e, pm1)
+	q := new(Fmpz).Quo(pm1, g)
+	u = new(Fmpz).Mod(u, p)
+	if !new(Fmpz).Exp(u, q, p).Equals(one) {
+		return nil
+	}
+
+	// Take a g-th root y of u one prime at a time. As e*t = g (mod p-1) for t = (e/g)^-1 modulo
+	// (p-1)/g, y^t is then an e-th root of u.
+	gFac := g.Factor()
+	y := new(Fmpz).Set(u)
+	for i, r := range gFac.Primes() {
+		for n := 0; n < gFac.GetExp(i); n++ {
+			y = amm(y, r, p)
+		}
+	}
+	x := NewFmpz(1)
+	if q.Cmp(one) > 0 {
+		t := new(Fmpz).ModInverse(new(Fmpz).Quo(e, g), q)
+		x.Exp(y, t, p)
+	}
+
+	// The other roots are x times the g-th roots of unity, which are the powers of a primitive one.
+	zeta := rootOfUnity(g, gFac, p)
+	var roots []*Fmpz
+	for i := new(Fmpz); i.Cmp(g) < 0; i.AddI(1) {
+		roots = append(roots, new(Fmpz).Set(x))
+		x.MulZ(zeta).ModZ(p)
+	}
+	return roots
+}
+
+// rootOfUnity returns a primitive g-th root of unity modulo the prime p, where g divides p-1 and
+// gFac is the factorization of g.
+func rootOfUnity(g *Fmpz, gFac *FmpzFactor, p *Fmpz) *Fmpz {
+	one := NewFmpz(1)
+	q := new(Fmpz).Quo(new(Fmpz).Sub(p, one), g)
+	zeta := new(Fmpz)
+	for h := NewFmpz(2); ; h.AddI(1) {
+		zeta.Exp(h, q, p)
+		primitive := true
+		for _, r := range gFac.Primes() {
+			if new(Fmpz).Exp(zeta, new(Fmpz).Quo(g, r), p).Equals(one) {
+				primitive = false
+				break
+			}
+		}
+		if primitive {
+			return zeta
+		}
+	}
+}
+
+// amm returns an r-th root of the r-th power residue delta modulo the prime p, where r is a prime
+// dividing p-1, using the Adleman-Manders-Miller algorithm. With p-1 = r^t * s and r coprime to s,
+// the root delta^alpha for r*alpha = 1 (mod s) is corrected by an element of the subgroup of order
+// r^t, found one base r digit at a time from discrete logarithms in the subgroup of order r.
+func amm(delta, r, p *Fmpz) *Fmpz {
+	one := NewFmpz(1)
+	pm1 := new(Fmpz).Sub(p, one)
+	s := new(Fmpz).Set(pm1)
+	t := 0
+	for rem := new(Fmpz); rem.Mod(s, r).IsZero(); t++ {
+		s.Quo(s, r)
+	}
+
+	// rho is any r-th power non-residue.
+	rho := NewFmpz(2)
+	q := new(Fmpz).Quo(pm1, r)
+	for new(Fmpz).Exp(rho, q, p).Equals(one) {
+		rho.AddI(1)
+	}
+
+	// Any alpha with r*alpha = 1 (mod s) will do, and alpha = 1 keeps r*alpha - 1 >= 0 when s = 1.
+	alpha := NewFmpz(1)
+	if s.Cmp(one) > 0 {
+		alpha.ModInverse(r, s)
+	}
+	rt1 := new(Fmpz).ExpXI(r, t-1)
+	a := new(Fmpz).Exp(rho, new(Fmpz).Mul(rt1, s), p)
+	b := new(Fmpz).Exp(delta, new(Fmpz).Mul(r, alpha).SubI(1), p)
+	c := new(Fmpz).Exp(rho, s, p)
+	h := NewFmpz(1)
+	d := new(Fmpz)
+	cr := new(Fmpz)
+	for i := 1; i < t; i++ {
+		d.Exp(b, new(Fmpz).ExpXI(r, t-1-i), p)
+
+		// Find j with a^j = d by stepping through the subgroup of order r, then negate it.
+		j := new(Fmpz)
+		if !d.Equals(one) {
+			for x := new(Fmpz).Set(a); !x.Equals(d); x.MulZ(a).ModZ(p) {
+				j.AddI(1)
+			}
+			j.AddI(1)
+			j.Sub(r, j)
+		}
+
+		cr.Exp(c, r, p)
+		b.MulZ(new(Fmpz).Exp(cr, j, p)).ModZ(p)
+		h.MulZ(new(Fmpz).Exp(c, j, p)).ModZ(p)
+		c.Set(cr)
+	}
+	return h.MulZ(new(Fmpz).Exp(delta, alpha, p)).ModZ(p)
+}
+
+// RootModFactored returns every e-th root of z modulo n in increasing order, where e >= 1 and fac
+// is the factorization of n. The roots modulo each prime power are found with RootModPrimePower,
+// so e need not be coprime to phi(n), and are combined by the Chinese remainder theorem. The
+// result is empty if z is not an e-th power modulo n, and an error is returned if n is not
+// positive.
+func (z *Fmpz) RootModFactored(e *Fmpz, fac *FmpzFactor) ([]*Fmpz, error) {
+	if e.Sign() <= 0 {
+		return nil, fmt.Errorf("RootModFactored: root %v is not positive", e)
+	}
+	moduli, err := factorPrimePowers("RootModFactored", fac)
+	if err != nil {
+		return nil, err
+	}
+
+	var perPrime [][]*Fmpz
+	for i, p := range fac.Primes() {
+		roots := z.RootModPrimePower(e, p, fac.GetExp(i))
+		if len(roots) == 0 {
+			return nil, nil
+		}
+		perPrime = append(perPrime, roots)
+	}
+	return crtCombineAll(moduli, perPrime)
+}
@@ -0,0 +1,356 @@
+package goflint
+
+import (
+	"testing"
+)
+
+func TestSqrtMod(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		a      string
+		p      string
+		wantOk bool
+	}{
+		{
+			name:   "square modulo a large prime",
+			a:      "643499475",
+			p:      "1000000007",
+			wantOk: true,
+		},
+		{
+			name:   "zero",
+			a:      "0",
+			p:      "1000000007",
+			wantOk: true,
+		},
+		{
+			name:   "non residue",
+			a:      "5",
+			p:      "1000000007",
+			wantOk: false,
+		},
+		{
+			name:   "prime congruent to 1 mod 8",
+			a:      "10",
+			p:      "4294967681",
+			wantOk: true,
+		},
+	} {
+		a, _ := new(Fmpz).SetString(tc.a, 10)
+		p, _ := new(Fmpz).SetString(tc.p, 10)
+
+		z := NewFmpz(99)
+		got, ok := z.SqrtMod(a, p)
+		if ok != tc.wantOk {
+			t.Fatalf("SqrtMod() %s want / got ok mismatch: %v / %v", tc.name, tc.wantOk, ok)
+		}
+		if !ok {
+			if z.String() != "99" {
+				t.Errorf("SqrtMod() %s modified z on failure: %v", tc.name, z)
+			}
+			continue
+		}
+		if sq := new(Fmpz).Exp(got, NewFmpz(2), p); !sq.Equals(new(Fmpz).Mod(a, p)) {
+			t.Errorf("SqrtMod() %s root %v squares to %v not %v", tc.name, got, sq, a)
+		}
+	}
+}
+
+func TestSqrtModPrimePower(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		z    int64
+		p    int64
+		k    int
+		want []string
+	}{
+		{
+			name: "odd power of two",
+			z:    17,
+			p:    2,
+			k:    5,
+			want: []string{"7", "9", "23", "25"},
+		},
+		{
+			name: "modulus 4",
+			z:    5,
+			p:    2,
+			k:    2,
+			want: []string{"1", "3"},
+		},
+		{
+			name: "not a square modulo 8",
+			z:    2,
+			p:    2,
+			k:    3,
+		},
+		{
+			name: "unit lifted to 13^6",
+			z:    1522756,
+			p:    13,
+			k:    6,
+			want: []string{"1234", "4825575"},
+		},
+		{
+			name: "non residue modulo 7",
+			z:    3,
+			p:    7,
+			k:    1,
+		},
+		{
+			name: "zero modulo 27",
+			z:    0,
+			p:    3,
+			k:    3,
+			want: []string{"0", "9", "18"},
+		},
+		{
+			name: "shares an even power of the prime",
+			z:    9,
+			p:    3,
+			k:    4,
+			want: []string{"3", "24", "30", "51", "57", "78"},
+		},
+		{
+			name: "shares an odd power of the prime",
+			z:    7,
+			p:    7,
+			k:    2,
+		},
+		{
+			name: "negative value",
+			z:    -2,
+			p:    3,
+			k:    1,
+			want: []string{"1", "2"},
+		},
+	} {
+		got := NewFmpz(tc.z).SqrtModPrimePower(NewFmpz(tc.p), tc.k)
+		if len(got) != len(tc.want) {
+			t.Fatalf("SqrtModPrimePower() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+		for i := range got {
+			if got[i].String() != tc.want[i] {
+				t.Errorf("SqrtModPrimePower() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestSqrtModFactored(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		z      string
+		primes []string
+		exps   []int
+		want   []string
+	}{
+		{
+			name:   "Rabin decryption",
+			z:      "26600208334850950433832961",
+			primes: []string{"1000000000039", "30000000000011"},
+			exps:   []int{1, 1},
+			want: []string{
+				"123456789012345678901",
+				"13771005124154061668956933",
+				"16228994877026938331043496",
+				"29999876544391987654321528",
+			},
+		},
+		{
+			name:   "small Rabin modulus",
+			z:      "15",
+			primes: []string{"7", "11"},
+			exps:   []int{1, 1},
+			want:   []string{"13", "20", "57", "64"},
+		},
+		{
+			name:   "prime powers sharing factors with z",
+			z:      "36",
+			primes: []string{"2", "3", "5"},
+			exps:   []int{3, 2, 1},
+			want:   []string{"6", "54", "66", "114", "126", "174", "186", "234", "246", "294", "306", "354"},
+		},
+		{
+			name:   "not a square modulo one prime",
+			z:      "3",
+			primes: []string{"7", "11"},
+			exps:   []int{1, 1},
+		},
+		{
+			name: "modulus one",
+			z:    "5",
+			want: []string{"0"},
+		},
+	} {
+		z, _ := new(Fmpz).SetString(tc.z, 10)
+		fac := NewFmpzFactor()
+		for i, p := range tc.primes {
+			pz, _ := new(Fmpz).SetString(p, 10)
+			fac.Append(pz, tc.exps[i])
+		}
+
+		got, err := z.SqrtModFactored(fac)
+		if err != nil {
+			t.Fatalf("SqrtModFactored() %s got error when not expected: %v", tc.name, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("SqrtModFactored() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+		for i := range got {
+			if got[i].String() != tc.want[i] {
+				t.Errorf("SqrtModFactored() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+				break
+			}
+		}
+	}
+
+	if _, err := NewFmpz(4).SqrtModFactored(NewFmpz(0).Factor()); err == nil {
+		t.Error("SqrtModFactored() expected error for a zero modulus but got none")
+	}
+}
+
+func TestRootModPrimePower(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		z    int64
+		e    int64
+		p    int64
+		k    int
+		want []string
+	}{
+		{
+			name: "cube roots of unity times two",
+			z:    8,
+			e:    3,
+			p:    7,
+			k:    1,
+			want: []string{"1", "2", "4"},
+		},
+		{
+			name: "sixth roots of unity",
+			z:    1,
+			e:    6,
+			p:    13,
+			k:    1,
+			want: []string{"1", "3", "4", "9", "10", "12"},
+		},
+		{
+			name: "not a cube",
+			z:    2,
+			e:    3,
+			p:    7,
+			k:    1,
+		},
+		{
+			name: "prime dividing e",
+			z:    10,
+			e:    3,
+			p:    3,
+			k:    3,
+			want: []string{"4", "13", "22"},
+		},
+		{
+			name: "fourth roots of one modulo 16",
+			z:    1,
+			e:    4,
+			p:    2,
+			k:    4,
+			want: []string{"1", "3", "5", "7", "9", "11", "13", "15"},
+		},
+		{
+			name: "square roots modulo 32",
+			z:    16,
+			e:    2,
+			p:    2,
+			k:    5,
+			want: []string{"4", "12", "20", "28"},
+		},
+		{
+			name: "zero modulo 25",
+			z:    0,
+			e:    3,
+			p:    5,
+			k:    2,
+			want: []string{"0", "5", "10", "15", "20"},
+		},
+		{
+			name: "first root",
+			z:    5,
+			e:    1,
+			p:    7,
+			k:    2,
+			want: []string{"5"},
+		},
+	} {
+		got := NewFmpz(tc.z).RootModPrimePower(NewFmpz(tc.e), NewFmpz(tc.p), tc.k)
+		if len(got) != len(tc.want) {
+			t.Fatalf("RootModPrimePower() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+		for i := range got {
+			if got[i].String() != tc.want[i] {
+				t.Errorf("RootModPrimePower() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestRootModFactored(t *testing.T) {
+	p, _ := new(Fmpz).SetString("1000000001051", 10)
+	q, _ := new(Fmpz).SetString("3000000000121", 10)
+	n := new(Fmpz).Mul(p, q)
+	m, _ := new(Fmpz).SetString("987654321987654321", 10)
+
+	for _, tc := range []struct {
+		name      string
+		c         string
+		e         int64
+		wantCount int
+	}{
+		{
+			// 25 divides p-1 and 5 divides q-1, so each prime contributes 5 roots.
+			name:      "e dividing both p-1 and q-1",
+			c:         "2167409585438506452487081",
+			e:         5,
+			wantCount: 25,
+		},
+		{
+			name:      "e coprime to phi(n)",
+			c:         "2586219328385203294394450",
+			e:         65537,
+			wantCount: 1,
+		},
+	} {
+		c, _ := new(Fmpz).SetString(tc.c, 10)
+		e := NewFmpz(tc.e)
+		got, err := c.RootModFactored(e, NewFmpzFactor().Append(p, 1).Append(q, 1))
+		if err != nil {
+			t.Fatalf("RootModFactored() %s got error when not expected: %v", tc.name, err)
+		}
+		if len(got) != tc.wantCount {
+			t.Fatalf("RootModFactored() %s number of roots want / got mismatch: %d / %d", tc.name, tc.wantCount, len(got))
+		}
+
+		found := false
+		for i, r := range got {
+			if !new(Fmpz).Exp(r, e, n).Equals(c) {
+				t.Errorf("RootModFactored() %s root %v is not an e-th root of %v", tc.name, r, c)
+			}
+			if i > 0 && got[i-1].Cmp(r) >= 0 {
+				t.Errorf("RootModFactored() %s roots not in increasing order: %v", tc.name, got)
+			}
+			if r.Equals(m) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("RootModFactored() %s roots do not include the message %v", tc.name, m)
+		}
+	}
+
+	if _, err := NewFmpz(4).RootModFactored(NewFmpz(0), NewFmpz(15).Factor()); err == nil {
+		t.Error("RootModFactored() expected error for a zero root but got none")
+	}
+}
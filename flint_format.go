@@ -0,0 +1,203 @@
+package goflint
+
+// This file implements fmt.Formatter support for Fmpz and Fmpq.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// formatBase returns the base and the '#' prefix for the verb ch, or false if ch is not
+// supported.
+func formatBase(ch rune) (int, string, bool) {
+	switch ch {
+	case 'b':
+		return 2, "0b", true
+	case 'o', 'O':
+		return 8, "0", true
+	case 'd', 's', 'v':
+		return 10, "", true
+	case 'x':
+		return 16, "0x", true
+	case 'X':
+		return 16, "0X", true
+	}
+	return 0, "", false
+}
+
+// digits returns the digits of |z| in the base and case required by the verb ch.
+func (z *Fmpz) digits(base int, ch rune) string {
+	d := new(Fmpz).Abs(z).string(base)
+	if ch == 'X' {
+		d = strings.ToUpper(d)
+	}
+	return d
+}
+
+// Format implements fmt.Formatter. It accepts the formats 'b' (binary), 'o' (octal with 0
+// prefix), 'O' (octal with 0o prefix), 'd' (decimal), 'x' (lowercase hexadecimal) and 'X'
+// (uppercase hexadecimal). Also supported are the full suite of package fmt's format flags for
+// integral types, including '+' and ' ' for sign control, '#' for leading zero in octal and for
+// hexadecimal, a leading "0x" or "0X" for "%#x" and "%#X" respectively, specification of minimum
+// digits precision, output field width, space or zero padding, and '-' for left or right
+// justification. This matches the behaviour of big.Int.
+func (z *Fmpz) Format(s fmt.State, ch rune) {
+	base, prefix, ok := formatBase(ch)
+	if !ok {
+		fmt.Fprintf(s, "%%!%c(goflint.Fmpz=%s)", ch, z.String())
+		return
+	}
+	if z == nil {
+		fmt.Fprint(s, "<nil>")
+		return
+	}
+
+	if ch == 'O' {
+		prefix = "0o"
+	} else if !s.Flag('#') {
+		prefix = ""
+	}
+
+	d := z.digits(base, ch)
+	if prec, ok := s.Precision(); ok {
+		if len(d) < prec {
+			d = strings.Repeat("0", prec-len(d)) + d
+		} else if d == "0" && prec == 0 {
+			// Print nothing if the value and the precision are both zero.
+			d = ""
+		}
+	}
+	// An octal zero already has its leading zero.
+	if ch == 'o' && d == "0" {
+		prefix = ""
+	}
+
+	writeFormatted(s, z.Sign() < 0, prefix+d)
+}
+
+// Format implements fmt.Formatter. It accepts the same formats and flags as Fmpz.Format and
+// writes the numerator and, if it is not 1, the denominator separated by a '/'. With the '#'
+// flag both parts carry the base prefix. Precision is not supported and is ignored.
+func (q *Fmpq) Format(s fmt.State, ch rune) {
+	base, prefix, ok := formatBase(ch)
+	if !ok {
+		fmt.Fprintf(s, "%%!%c(goflint.Fmpq=%s)", ch, q.String())
+		return
+	}
+	if q == nil {
+		fmt.Fprint(s, "<nil>")
+		return
+	}
+
+	if ch == 'O' {
+		prefix = "0o"
+	} else if !s.Flag('#') {
+		prefix = ""
+	}
+
+	part := func(z *Fmpz) string {
+		d := z.digits(base, ch)
+		// An octal zero already has its leading zero.
+		if ch == 'o' && d == "0" {
+			return d
+		}
+		return prefix + d
+	}
+
+	num, den := q.fmpqParts()
+	body := part(num)
+	if den.Cmp(NewFmpz(1)) != 0 {
+		body += "/" + part(den)
+	}
+
+	writeFormatted(s, num.Sign() < 0, body)
+}
+
+// writeFormatted writes the sign and body to s padded to the width requested in s.
+func writeFormatted(s fmt.State, neg bool, body string) {
+	sign := ""
+	switch {
+	case neg:
+		sign = "-"
+	case s.Flag('+'):
+		sign = "+"
+	case s.Flag(' '):
+		sign = " "
+	}
+
+	width, ok := s.Width()
+	pad := width - len(sign) - len(body)
+	if !ok || pad <= 0 {
+		fmt.Fprint(s, sign+body)
+		return
+	}
+
+	_, hasPrec := s.Precision()
+	switch {
+	case s.Flag('-'):
+		fmt.Fprint(s, sign+body+strings.Repeat(" ", pad))
+	case s.Flag('0') && !hasPrec:
+		// Zero padding goes after the sign and any base prefix, matching big.Int.
+		fmt.Fprint(s, sign+zeroPad(body, pad))
+	default:
+		fmt.Fprint(s, strings.Repeat(" ", pad)+sign+body)
+	}
+}
+
+// zeroPad inserts pad zeros into body after any base prefix.
+func zeroPad(body string, pad int) string {
+	for _, p := range []string{"0b", "0o", "0x", "0X"} {
+		if strings.HasPrefix(body, p) {
+			return p + strings.Repeat("0", pad) + body[len(p):]
+		}
+	}
+	return strings.Repeat("0", pad) + body
+}
+
+// GroupDigits returns the representation of z in the given base with the digits split into
+// groups of size digits separated by sep, counting from the least significant digit,
+// e.g. GroupDigits(10, 3, ",") of 1234567 is "1,234,567".
+func (z *Fmpz) GroupDigits(base, size int, sep string) string {
+	if z == nil {
+		return "<nil>"
+	}
+	d := z.digits(base, 'x')
+	if size <= 0 || len(d) <= size {
+		return z.string(base)
+	}
+
+	var b strings.Builder
+	if z.Sign() < 0 {
+		b.WriteByte('-')
+	}
+	first := len(d) % size
+	if first == 0 {
+		first = size
+	}
+	b.WriteString(d[:first])
+	for i := first; i < len(d); i += size {
+		b.WriteString(sep)
+		b.WriteString(d[i : i+size])
+	}
+	return b.String()
+}
+
+// Abbrev returns an abbreviated representation of z in the given base, suitable for logging
+// very large values. If z has more than 2*n digits only the first and last n digits are kept
+// along with the total digit count, e.g. "1234...6789 (1500 digits)".
+func (z *Fmpz) Abbrev(base, n int) string {
+	if z == nil {
+		return "<nil>"
+	}
+	d := z.digits(base, 'x')
+	if n <= 0 || len(d) <= 2*n {
+		return z.string(base)
+	}
+
+	sign := ""
+	if z.Sign() < 0 {
+		sign = "-"
+	}
+	return sign + d[:n] + "..." + d[len(d)-n:] + " (" + strconv.Itoa(len(d)) + " digits)"
+}
@@ -0,0 +1,149 @@
+package goflint
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFmpzFormat(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		n      int64
+		want   string
+	}{
+		{format: "%d", n: 255, want: "255"},
+		{format: "%v", n: -255, want: "-255"},
+		{format: "%s", n: 255, want: "255"},
+		{format: "%x", n: 255, want: "ff"},
+		{format: "%X", n: -255, want: "-FF"},
+		{format: "%#x", n: 255, want: "0xff"},
+		{format: "%#X", n: 255, want: "0XFF"},
+		{format: "%o", n: 8, want: "10"},
+		{format: "%#o", n: 8, want: "010"},
+		{format: "%O", n: 8, want: "0o10"},
+		{format: "%b", n: 5, want: "101"},
+		{format: "%#b", n: 5, want: "0b101"},
+		{format: "%+d", n: 42, want: "+42"},
+		{format: "% d", n: 42, want: " 42"},
+		{format: "%6d", n: -42, want: "   -42"},
+		{format: "%-6d|", n: 42, want: "42    |"},
+		{format: "%06d", n: -42, want: "-00042"},
+		{format: "%#08x", n: 255, want: "0x0000ff"},
+		{format: "%.4d", n: 42, want: "0042"},
+		{format: "%8.4x", n: 255, want: "    00ff"},
+		{format: "%.0d", n: 0, want: ""},
+		{format: "%q", n: 1, want: "%!q(goflint.Fmpz=1)"},
+	} {
+		got := fmt.Sprintf(tc.format, NewFmpz(tc.n))
+		if got != tc.want {
+			t.Errorf("Format() %q of %d want / got mismatch: %q / %q", tc.format, tc.n, tc.want, got)
+		}
+	}
+}
+
+func TestFmpzFormatNil(t *testing.T) {
+	var z *Fmpz
+	if got := fmt.Sprintf("%x", z); got != "<nil>" {
+		t.Errorf("Format() of nil want / got mismatch: %q / %q", "<nil>", got)
+	}
+}
+
+func TestFmpqFormat(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		q      *Fmpq
+		want   string
+	}{
+		{format: "%v", q: NewFmpq(-3, 2), want: "-3/2"},
+		{format: "%x", q: NewFmpq(255, 16), want: "ff/10"},
+		{format: "%#x", q: NewFmpq(-255, 16), want: "-0xff/0x10"},
+		{format: "%+d", q: NewFmpq(7, 1), want: "+7"},
+		{format: "%8v|", q: NewFmpq(1, 3), want: "     1/3|"},
+		{format: "%-8v|", q: NewFmpq(1, 3), want: "1/3     |"},
+		{format: "%#o", q: NewFmpq(0, 1), want: "0"},
+		{format: "%#o", q: NewFmpq(-8, 9), want: "-010/011"},
+	} {
+		got := fmt.Sprintf(tc.format, tc.q)
+		if got != tc.want {
+			t.Errorf("Format() %q of %v want / got mismatch: %q / %q", tc.format, tc.q.String(), tc.want, got)
+		}
+	}
+}
+
+func TestGroupDigits(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		n    string
+		base int
+		size int
+		sep  string
+		want string
+	}{
+		{
+			name: "thousands",
+			n:    "1234567",
+			base: 10,
+			size: 3,
+			sep:  ",",
+			want: "1,234,567",
+		},
+		{
+			name: "negative exact groups",
+			n:    "-123456",
+			base: 10,
+			size: 3,
+			sep:  "_",
+			want: "-123_456",
+		},
+		{
+			name: "hex words",
+			n:    "1311768467463790320",
+			base: 16,
+			size: 4,
+			sep:  " ",
+			want: "1234 5678 9abc def0",
+		},
+		{
+			name: "shorter than a group",
+			n:    "12",
+			base: 10,
+			size: 3,
+			sep:  ",",
+			want: "12",
+		},
+	} {
+		n, _ := new(Fmpz).SetString(tc.n, 10)
+		got := n.GroupDigits(tc.base, tc.size, tc.sep)
+		if got != tc.want {
+			t.Errorf("GroupDigits() %s want / got mismatch: %q / %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestAbbrev(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		n    string
+		d    int
+		want string
+	}{
+		{
+			name: "large value",
+			n:    "-833810193564967701912362955539789451139872863794534923259743419423089229206473091408403560311191545764221310666338878019",
+			d:    4,
+			want: "-8338...8019 (120 digits)",
+		},
+		{
+			name: "short value is not abbreviated",
+			n:    "12345678",
+			d:    4,
+			want: "12345678",
+		},
+	} {
+		n, _ := new(Fmpz).SetString(tc.n, 10)
+		got := n.Abbrev(10, tc.d)
+		if got != tc.want {
+			t.Errorf("Abbrev() %s want / got mismatch: %q / %q", tc.name, tc.want, got)
+		}
+	}
+}
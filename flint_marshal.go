@@ -0,0 +1,601 @@
+package goflint
+
+// This file implements encoding/gob, encoding and encoding/json support for the goflint types.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// marshalVersion is the version of the binary encodings produced by MarshalBinary.
+const marshalVersion byte = 1
+
+// maxMatDim is the largest row or column count accepted when decoding an FmpzMat. Each dimension
+// is bounded on its own, as a zero in the other leaves the entry count no help against a huge
+// allocation in fmpz_mat_init. It also keeps rows*cols well inside a uint64.
+const maxMatDim = 1 << 20
+
+/*
+ * Fmpz
+ */
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (z *Fmpz) MarshalText() ([]byte, error) {
+	if z == nil {
+		return []byte("<nil>"), nil
+	}
+	return []byte(z.string(10)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. The base prefixes accepted by
+// SetString with base 0 are recognised.
+func (z *Fmpz) UnmarshalText(text []byte) error {
+	if _, ok := z.SetString(string(text), 0); !ok {
+		return fmt.Errorf("goflint: cannot unmarshal %q into a *goflint.Fmpz", text)
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value is encoded as a JSON number.
+func (z *Fmpz) MarshalJSON() ([]byte, error) {
+	if z == nil {
+		return []byte("null"), nil
+	}
+	return []byte(z.string(10)), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. A JSON null leaves z unchanged.
+func (z *Fmpz) UnmarshalJSON(text []byte) error {
+	if string(text) == "null" {
+		return nil
+	}
+	return z.UnmarshalText(text)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The encoding is a version byte
+// with the sign in the low bit followed by the big-endian absolute value.
+func (z *Fmpz) MarshalBinary() ([]byte, error) {
+	if z == nil {
+		return nil, nil
+	}
+	b := marshalVersion << 1
+	if z.Sign() < 0 {
+		b |= 1
+	}
+	return append([]byte{b}, z.Bytes()...), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (z *Fmpz) UnmarshalBinary(buf []byte) error {
+	if len(buf) == 0 {
+		z.SetInt64(0)
+		return nil
+	}
+	if buf[0]>>1 != marshalVersion {
+		return fmt.Errorf("goflint: Fmpz encoding version %d not supported", buf[0]>>1)
+	}
+	z.SetBytes(buf[1:])
+	if buf[0]&1 != 0 {
+		z.Neg(z)
+	}
+	return nil
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (z *Fmpz) GobEncode() ([]byte, error) {
+	return z.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (z *Fmpz) GobDecode(buf []byte) error {
+	return z.UnmarshalBinary(buf)
+}
+
+// appendFmpz appends the length prefixed binary encoding of z to buf.
+func appendFmpz(buf []byte, z *Fmpz) []byte {
+	b, _ := z.MarshalBinary()
+	buf = appendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// readFmpz decodes a length prefixed Fmpz from the front of buf and returns it along with the
+// remainder of buf.
+func readFmpz(buf []byte) (*Fmpz, []byte, error) {
+	l, rest, err := readUvarint(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	if uint64(len(rest)) < l {
+		return nil, nil, errors.New("goflint: truncated encoding")
+	}
+	z := new(Fmpz)
+	if err := z.UnmarshalBinary(rest[:l]); err != nil {
+		return nil, nil, err
+	}
+	return z, rest[l:], nil
+}
+
+// appendUvarint appends the varint encoding of x to buf.
+func appendUvarint(buf []byte, x uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], x)
+	return append(buf, tmp[:n]...)
+}
+
+// readUvarint decodes a varint from the front of buf and returns it along with the remainder of
+// buf.
+func readUvarint(buf []byte) (uint64, []byte, error) {
+	x, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return 0, nil, errors.New("goflint: invalid length in encoding")
+	}
+	return x, buf[n:], nil
+}
+
+// checkVersion checks and strips the version byte at the front of buf.
+func checkVersion(buf []byte, typ string) ([]byte, error) {
+	if len(buf) == 0 {
+		return nil, fmt.Errorf("goflint: empty %s encoding", typ)
+	}
+	if buf[0] != marshalVersion {
+		return nil, fmt.Errorf("goflint: %s encoding version %d not supported", typ, buf[0])
+	}
+	return buf[1:], nil
+}
+
+/*
+ * Fmpq
+ */
+
+// MarshalText implements the encoding.TextMarshaler interface. The value is encoded as "a/b", or
+// just "a" when the denominator is 1.
+func (q *Fmpq) MarshalText() ([]byte, error) {
+	if q == nil {
+		return []byte("<nil>"), nil
+	}
+	return []byte(q.string(10)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. Any string accepted by
+// SetString in base 10 is decoded, including exact decimals.
+func (q *Fmpq) UnmarshalText(text []byte) error {
+	if _, ok := q.SetString(string(text), 10); !ok {
+		return fmt.Errorf("goflint: cannot unmarshal %q into a *goflint.Fmpq", text)
+	}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value is encoded as a JSON string.
+func (q *Fmpq) MarshalJSON() ([]byte, error) {
+	if q == nil {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.Quote(q.string(10))), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. A JSON null leaves q unchanged.
+func (q *Fmpq) UnmarshalJSON(text []byte) error {
+	if string(text) == "null" {
+		return nil
+	}
+	s, err := strconv.Unquote(string(text))
+	if err != nil {
+		return fmt.Errorf("goflint: cannot unmarshal %s into a *goflint.Fmpq", text)
+	}
+	return q.UnmarshalText([]byte(s))
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The encoding is a version byte
+// followed by the length prefixed numerator and denominator.
+func (q *Fmpq) MarshalBinary() ([]byte, error) {
+	if q == nil {
+		return nil, nil
+	}
+	num, den := q.fmpqParts()
+	buf := []byte{marshalVersion}
+	buf = appendFmpz(buf, num)
+	return appendFmpz(buf, den), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (q *Fmpq) UnmarshalBinary(buf []byte) error {
+	buf, err := checkVersion(buf, "Fmpq")
+	if err != nil {
+		return err
+	}
+	num, buf, err := readFmpz(buf)
+	if err != nil {
+		return err
+	}
+	den, _, err := readFmpz(buf)
+	if err != nil {
+		return err
+	}
+	if den.IsZero() {
+		return errors.New("goflint: zero denominator in Fmpq encoding")
+	}
+	q.SetFmpqFraction(num, den)
+	return nil
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (q *Fmpq) GobEncode() ([]byte, error) {
+	return q.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (q *Fmpq) GobDecode(buf []byte) error {
+	return q.UnmarshalBinary(buf)
+}
+
+/*
+ * FmpzPoly
+ */
+
+// MarshalText implements the encoding.TextMarshaler interface using the StringSimple format
+// e.g. f(x)=5x^3+2x+1 is "4  1 2 0 5".
+func (z *FmpzPoly) MarshalText() ([]byte, error) {
+	if z == nil {
+		return []byte("<nil>"), nil
+	}
+	z.fmpzPolyDoinit()
+	return []byte(z.StringSimple()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (z *FmpzPoly) UnmarshalText(text []byte) error {
+	z.fmpzPolyDoinit()
+	if strings.TrimSpace(string(text)) == "0" {
+		z.Zero()
+		return nil
+	}
+	p, err := FmpzPolySetString(string(text))
+	if err != nil {
+		return fmt.Errorf("goflint: cannot unmarshal %q into a *goflint.FmpzPoly: %v", text, err)
+	}
+	z.Set(p)
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value is encoded as a JSON string in
+// the StringSimple format.
+func (z *FmpzPoly) MarshalJSON() ([]byte, error) {
+	if z == nil {
+		return []byte("null"), nil
+	}
+	text, _ := z.MarshalText()
+	return []byte(strconv.Quote(string(text))), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. A JSON null leaves z unchanged.
+func (z *FmpzPoly) UnmarshalJSON(text []byte) error {
+	if string(text) == "null" {
+		return nil
+	}
+	s, err := strconv.Unquote(string(text))
+	if err != nil {
+		return fmt.Errorf("goflint: cannot unmarshal %s into a *goflint.FmpzPoly", text)
+	}
+	return z.UnmarshalText([]byte(s))
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The encoding is a version byte
+// followed by the number of coefficients and each length prefixed coefficient in increasing
+// order of degree.
+func (z *FmpzPoly) MarshalBinary() ([]byte, error) {
+	if z == nil {
+		return nil, nil
+	}
+	z.fmpzPolyDoinit()
+	coeffs := z.GetCoeffs()
+	buf := appendUvarint([]byte{marshalVersion}, uint64(len(coeffs)))
+	for _, c := range coeffs {
+		buf = appendFmpz(buf, c)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (z *FmpzPoly) UnmarshalBinary(buf []byte) error {
+	buf, err := checkVersion(buf, "FmpzPoly")
+	if err != nil {
+		return err
+	}
+	l, buf, err := readUvarint(buf)
+	if err != nil {
+		return err
+	}
+	p := NewFmpzPoly()
+	for i := 0; uint64(i) < l; i++ {
+		var c *Fmpz
+		if c, buf, err = readFmpz(buf); err != nil {
+			return err
+		}
+		p.SetCoeff(i, c)
+	}
+	z.fmpzPolyDoinit()
+	z.Set(p)
+	return nil
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (z *FmpzPoly) GobEncode() ([]byte, error) {
+	return z.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (z *FmpzPoly) GobDecode(buf []byte) error {
+	return z.UnmarshalBinary(buf)
+}
+
+/*
+ * FmpzModPoly
+ */
+
+// MarshalText implements the encoding.TextMarshaler interface using the StringSimple format
+// which includes the modulus e.g. f(x)=5x^3+2x+1 in (Z/6Z)[x] is "4 6  1 2 0 5".
+func (z *FmpzModPoly) MarshalText() ([]byte, error) {
+	if z == nil {
+		return []byte("<nil>"), nil
+	}
+	return []byte(z.StringSimple()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. The modulus context of z is
+// replaced by the modulus in text.
+func (z *FmpzModPoly) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	var p *FmpzModPoly
+	if f := strings.Fields(s); len(f) == 2 && f[0] == "0" {
+		// The zero polynomial has no coefficients after the modulus.
+		n, ok := new(Fmpz).SetString(f[1], 10)
+		if !ok {
+			return fmt.Errorf("goflint: cannot unmarshal %q into a *goflint.FmpzModPoly", text)
+		}
+		p = NewFmpzModPoly(NewFmpzModCtx(n))
+	} else {
+		var err error
+		if p, err = SetString(s); err != nil {
+			return fmt.Errorf("goflint: cannot unmarshal %q into a *goflint.FmpzModPoly: %v", text, err)
+		}
+	}
+	z.setModPoly(p)
+	return nil
+}
+
+// setModPoly sets z to p, replacing the modulus context of z by that of p.
+func (z *FmpzModPoly) setModPoly(p *FmpzModPoly) {
+	fmpzModPolyFinalize(z)
+	z.fmpzModPolyDoinit(p.ctx)
+	z.ctx = p.ctx
+	z.Set(p)
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value is encoded as a JSON string in
+// the StringSimple format.
+func (z *FmpzModPoly) MarshalJSON() ([]byte, error) {
+	if z == nil {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.Quote(z.StringSimple())), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. A JSON null leaves z unchanged.
+func (z *FmpzModPoly) UnmarshalJSON(text []byte) error {
+	if string(text) == "null" {
+		return nil
+	}
+	s, err := strconv.Unquote(string(text))
+	if err != nil {
+		return fmt.Errorf("goflint: cannot unmarshal %s into a *goflint.FmpzModPoly", text)
+	}
+	return z.UnmarshalText([]byte(s))
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The encoding is a version byte
+// followed by the length prefixed modulus, the number of coefficients and each length prefixed
+// coefficient in increasing order of degree.
+func (z *FmpzModPoly) MarshalBinary() ([]byte, error) {
+	if z == nil {
+		return nil, nil
+	}
+	buf := appendFmpz([]byte{marshalVersion}, z.GetMod())
+	coeffs := z.GetCoeffs()
+	buf = appendUvarint(buf, uint64(len(coeffs)))
+	for _, c := range coeffs {
+		buf = appendFmpz(buf, c)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. The modulus context of z
+// is replaced by the encoded modulus.
+func (z *FmpzModPoly) UnmarshalBinary(buf []byte) error {
+	buf, err := checkVersion(buf, "FmpzModPoly")
+	if err != nil {
+		return err
+	}
+	n, buf, err := readFmpz(buf)
+	if err != nil {
+		return err
+	}
+	if n.Sign() <= 0 {
+		return errors.New("goflint: non-positive modulus in FmpzModPoly encoding")
+	}
+	l, buf, err := readUvarint(buf)
+	if err != nil {
+		return err
+	}
+	p := NewFmpzModPoly(NewFmpzModCtx(n))
+	for i := 0; uint64(i) < l; i++ {
+		var c *Fmpz
+		if c, buf, err = readFmpz(buf); err != nil {
+			return err
+		}
+		p.SetCoeff(i, c)
+	}
+	z.setModPoly(p)
+	return nil
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (z *FmpzModPoly) GobEncode() ([]byte, error) {
+	return z.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (z *FmpzModPoly) GobDecode(buf []byte) error {
+	return z.UnmarshalBinary(buf)
+}
+
+/*
+ * FmpzMat
+ */
+
+// MarshalText implements the encoding.TextMarshaler interface. The matrix is encoded as the
+// number of rows and columns followed by the entries in row major order, in the same format as
+// fmpz_mat_fprint e.g. "2 2  1 0 0 1".
+func (m *FmpzMat) MarshalText() ([]byte, error) {
+	if m == nil {
+		return []byte("<nil>"), nil
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%d %d ", m.NumRows(), m.NumCols())
+	for r := 0; r < m.NumRows(); r++ {
+		for c := 0; c < m.NumCols(); c++ {
+			fmt.Fprintf(&b, " %v", m.entry(r, c))
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. The dimensions of m are
+// replaced by the encoded dimensions.
+func (m *FmpzMat) UnmarshalText(text []byte) error {
+	f := strings.Fields(string(text))
+	if len(f) < 2 {
+		return fmt.Errorf("goflint: cannot unmarshal %q into a *goflint.FmpzMat", text)
+	}
+	rows, err := strconv.ParseUint(f[0], 10, 64)
+	if err != nil || rows > maxMatDim {
+		return fmt.Errorf("goflint: cannot unmarshal %q into a *goflint.FmpzMat: bad row count", text)
+	}
+	cols, err := strconv.ParseUint(f[1], 10, 64)
+	if err != nil || cols > maxMatDim {
+		return fmt.Errorf("goflint: cannot unmarshal %q into a *goflint.FmpzMat: bad column count", text)
+	}
+	if uint64(len(f)-2) != rows*cols {
+		return fmt.Errorf("goflint: cannot unmarshal %q into a *goflint.FmpzMat: want %d entries got %d", text, rows*cols, len(f)-2)
+	}
+
+	entries := make([]*Fmpz, 0, len(f)-2)
+	for _, e := range f[2:] {
+		v, ok := new(Fmpz).SetString(e, 10)
+		if !ok {
+			return fmt.Errorf("goflint: cannot unmarshal %q into a *goflint.FmpzMat: bad entry %q", text, e)
+		}
+		entries = append(entries, v)
+	}
+	m.setEntries(int(rows), int(cols), entries)
+	return nil
+}
+
+// setEntries resizes m to rows * cols and sets its entries from a row major slice.
+func (m *FmpzMat) setEntries(rows, cols int, entries []*Fmpz) {
+	if m.init && (m.rows != rows || m.cols != cols) {
+		fmpzMatFinalize(m)
+	}
+	if err := m.fmpzMatDoinit(rows, cols); err != nil {
+		panic(err)
+	}
+	for i, e := range entries {
+		m.SetVal(e, i%cols, i/cols)
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value is encoded as a JSON string in
+// the MarshalText format.
+func (m *FmpzMat) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	text, _ := m.MarshalText()
+	return []byte(strconv.Quote(string(text))), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. A JSON null leaves m unchanged.
+func (m *FmpzMat) UnmarshalJSON(text []byte) error {
+	if string(text) == "null" {
+		return nil
+	}
+	s, err := strconv.Unquote(string(text))
+	if err != nil {
+		return fmt.Errorf("goflint: cannot unmarshal %s into a *goflint.FmpzMat", text)
+	}
+	return m.UnmarshalText([]byte(s))
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The encoding is a version byte
+// followed by the number of rows and columns and each length prefixed entry in row major order.
+func (m *FmpzMat) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	buf := appendUvarint([]byte{marshalVersion}, uint64(m.NumRows()))
+	buf = appendUvarint(buf, uint64(m.NumCols()))
+	for r := 0; r < m.NumRows(); r++ {
+		for c := 0; c < m.NumCols(); c++ {
+			buf = appendFmpz(buf, m.entry(r, c))
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface. The dimensions of m are
+// replaced by the encoded dimensions.
+func (m *FmpzMat) UnmarshalBinary(buf []byte) error {
+	buf, err := checkVersion(buf, "FmpzMat")
+	if err != nil {
+		return err
+	}
+	rows, buf, err := readUvarint(buf)
+	if err != nil {
+		return err
+	}
+	cols, buf, err := readUvarint(buf)
+	if err != nil {
+		return err
+	}
+	if rows > maxMatDim || cols > maxMatDim {
+		return fmt.Errorf("goflint: FmpzMat dimensions %dx%d too large", rows, cols)
+	}
+	// Every entry takes at least two bytes so bound the entry count by the input size.
+	if rows*cols > uint64(len(buf)) {
+		return errors.New("goflint: truncated FmpzMat encoding")
+	}
+
+	entries := make([]*Fmpz, 0, rows*cols)
+	for i := uint64(0); i < rows*cols; i++ {
+		var e *Fmpz
+		if e, buf, err = readFmpz(buf); err != nil {
+			return err
+		}
+		entries = append(entries, e)
+	}
+	m.setEntries(int(rows), int(cols), entries)
+	return nil
+}
+
+// GobEncode implements the gob.GobEncoder interface.
+func (m *FmpzMat) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (m *FmpzMat) GobDecode(buf []byte) error {
+	return m.UnmarshalBinary(buf)
+}
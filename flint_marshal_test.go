@@ -0,0 +1,313 @@
+package goflint
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"strconv"
+	"testing"
+)
+
+func TestFmpzMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		n    string
+	}{
+		{
+			name: "zero",
+			n:    "0",
+		},
+		{
+			name: "negative",
+			n:    "-42",
+		},
+		{
+			name: "large",
+			n:    "833810193564967701912362955539789451139872863794534923259743419423089229206473091408403560311191545764221310666338878019",
+		},
+	} {
+		want, _ := new(Fmpz).SetString(tc.n, 10)
+
+		text, err := want.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText() %s got error when not expected: %v", tc.name, err)
+		}
+		got := new(Fmpz)
+		if err := got.UnmarshalText(text); err != nil || got.Cmp(want) != 0 {
+			t.Errorf("UnmarshalText() %s want / got mismatch: %v / %v (err %v)", tc.name, want, got, err)
+		}
+
+		bin, err := want.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() %s got error when not expected: %v", tc.name, err)
+		}
+		got = new(Fmpz)
+		if err := got.UnmarshalBinary(bin); err != nil || got.Cmp(want) != 0 {
+			t.Errorf("UnmarshalBinary() %s want / got mismatch: %v / %v (err %v)", tc.name, want, got, err)
+		}
+
+		js, err := json.Marshal(want)
+		if err != nil {
+			t.Fatalf("json.Marshal() %s got error when not expected: %v", tc.name, err)
+		}
+		if string(js) != tc.n {
+			t.Errorf("json.Marshal() %s want / got mismatch: %s / %s", tc.name, tc.n, js)
+		}
+		got = new(Fmpz)
+		if err := json.Unmarshal(js, got); err != nil || got.Cmp(want) != 0 {
+			t.Errorf("json.Unmarshal() %s want / got mismatch: %v / %v (err %v)", tc.name, want, got, err)
+		}
+	}
+}
+
+func TestFmpzUnmarshalTextError(t *testing.T) {
+	if err := new(Fmpz).UnmarshalText([]byte("12ab")); err == nil {
+		t.Error("UnmarshalText() expected error for invalid text but got nil")
+	}
+}
+
+func TestFmpqMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		q    *Fmpq
+		want string
+	}{
+		{
+			name: "fraction",
+			q:    NewFmpq(-3, 2),
+			want: `"-3/2"`,
+		},
+		{
+			name: "integer",
+			q:    NewFmpq(7, 1),
+			want: `"7"`,
+		},
+	} {
+		js, err := json.Marshal(tc.q)
+		if err != nil {
+			t.Fatalf("json.Marshal() %s got error when not expected: %v", tc.name, err)
+		}
+		if string(js) != tc.want {
+			t.Errorf("json.Marshal() %s want / got mismatch: %s / %s", tc.name, tc.want, js)
+		}
+		got := new(Fmpq)
+		if err := json.Unmarshal(js, got); err != nil || got.Cmp(tc.q) != 0 {
+			t.Errorf("json.Unmarshal() %s want / got mismatch: %v / %v (err %v)", tc.name, tc.q, got, err)
+		}
+
+		bin, err := tc.q.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() %s got error when not expected: %v", tc.name, err)
+		}
+		got = new(Fmpq)
+		if err := got.UnmarshalBinary(bin); err != nil || got.Cmp(tc.q) != 0 {
+			t.Errorf("UnmarshalBinary() %s want / got mismatch: %v / %v (err %v)", tc.name, tc.q, got, err)
+		}
+	}
+}
+
+func TestFmpzPolyMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		p    *FmpzPoly
+	}{
+		{
+			name: "f(x)=5x^3+2x+1",
+			p:    NewFmpzPoly().SetCoeffUI(0, 1).SetCoeffUI(1, 2).SetCoeffUI(2, 0).SetCoeffUI(3, 5),
+		},
+		{
+			name: "zero polynomial",
+			p:    NewFmpzPoly(),
+		},
+	} {
+		text, _ := tc.p.MarshalText()
+		got := new(FmpzPoly)
+		if err := got.UnmarshalText(text); err != nil || !got.Equal(tc.p) {
+			t.Errorf("UnmarshalText() %s want / got mismatch: %v / %v (err %v)", tc.name, tc.p, got, err)
+		}
+
+		js, err := json.Marshal(tc.p)
+		if err != nil {
+			t.Fatalf("json.Marshal() %s got error when not expected: %v", tc.name, err)
+		}
+		got = new(FmpzPoly)
+		if err := json.Unmarshal(js, got); err != nil || !got.Equal(tc.p) {
+			t.Errorf("json.Unmarshal() %s want / got mismatch: %v / %v (err %v)", tc.name, tc.p, got, err)
+		}
+
+		bin, _ := tc.p.MarshalBinary()
+		got = new(FmpzPoly)
+		if err := got.UnmarshalBinary(bin); err != nil || !got.Equal(tc.p) {
+			t.Errorf("UnmarshalBinary() %s want / got mismatch: %v / %v (err %v)", tc.name, tc.p, got, err)
+		}
+	}
+}
+
+func TestFmpzModPolyMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		p    *FmpzModPoly
+	}{
+		{
+			name: "f(x)=5x^3+2x+1 in (Z/6Z)[x]",
+			p:    NewFmpzModPoly(NewFmpzModCtx(NewFmpz(6))).SetCoeffUI(0, 1).SetCoeffUI(1, 2).SetCoeffUI(2, 0).SetCoeffUI(3, 5),
+		},
+		{
+			name: "zero polynomial in (Z/7Z)[x]",
+			p:    NewFmpzModPoly(NewFmpzModCtx(NewFmpz(7))),
+		},
+	} {
+		want := tc.p.StringSimple()
+
+		text, _ := tc.p.MarshalText()
+		got := new(FmpzModPoly)
+		if err := got.UnmarshalText(text); err != nil || got.StringSimple() != want {
+			t.Errorf("UnmarshalText() %s want / got mismatch: %v / %v (err %v)", tc.name, want, got.StringSimple(), err)
+		}
+
+		// Decoding into a polynomial with a different modulus replaces the modulus.
+		js, _ := json.Marshal(tc.p)
+		got = NewFmpzModPoly(NewFmpzModCtx(NewFmpz(101)))
+		if err := json.Unmarshal(js, got); err != nil || got.StringSimple() != want {
+			t.Errorf("json.Unmarshal() %s want / got mismatch: %v / %v (err %v)", tc.name, want, got.StringSimple(), err)
+		}
+
+		bin, _ := tc.p.MarshalBinary()
+		got = new(FmpzModPoly)
+		if err := got.UnmarshalBinary(bin); err != nil || got.StringSimple() != want || got.GetMod().Cmp(tc.p.GetMod()) != 0 {
+			t.Errorf("UnmarshalBinary() %s want / got mismatch: %v / %v (err %v)", tc.name, want, got.StringSimple(), err)
+		}
+	}
+}
+
+func TestFmpzMatMarshal(t *testing.T) {
+	m := NewFmpzMat(2, 3)
+	m.SetVal(NewFmpz(1), 0, 0).SetVal(NewFmpz(-2), 1, 0).SetVal(NewFmpz(3), 2, 0)
+	m.SetVal(NewFmpz(4), 0, 1).SetVal(NewFmpz(5), 1, 1).SetVal(NewFmpz(-6), 2, 1)
+
+	text, _ := m.MarshalText()
+	if want := "2 3  1 -2 3 4 5 -6"; string(text) != want {
+		t.Errorf("MarshalText() want / got mismatch: %q / %q", want, text)
+	}
+
+	check := func(method string, got *FmpzMat) {
+		if got.NumRows() != 2 || got.NumCols() != 3 {
+			t.Fatalf("%s dimensions want / got mismatch: 2x3 / %dx%d", method, got.NumRows(), got.NumCols())
+		}
+		for r := 0; r < 2; r++ {
+			for c := 0; c < 3; c++ {
+				if got.entry(r, c).Cmp(m.entry(r, c)) != 0 {
+					t.Errorf("%s entry %d,%d want / got mismatch: %v / %v", method, r, c, m.entry(r, c), got.entry(r, c))
+				}
+			}
+		}
+	}
+
+	got := NewFmpzMat(1, 1)
+	if err := got.UnmarshalText(text); err != nil {
+		t.Fatalf("UnmarshalText() got error when not expected: %v", err)
+	}
+	check("UnmarshalText()", got)
+
+	js, _ := json.Marshal(m)
+	got = new(FmpzMat)
+	if err := json.Unmarshal(js, got); err != nil {
+		t.Fatalf("json.Unmarshal() got error when not expected: %v", err)
+	}
+	check("json.Unmarshal()", got)
+
+	bin, _ := m.MarshalBinary()
+	got = new(FmpzMat)
+	if err := got.UnmarshalBinary(bin); err != nil {
+		t.Fatalf("UnmarshalBinary() got error when not expected: %v", err)
+	}
+	check("UnmarshalBinary()", got)
+}
+
+func TestFmpzMatUnmarshalError(t *testing.T) {
+	for _, text := range []string{
+		"4294967296 4294967296",
+		"1000000000000 0",
+		"0 1000000000000",
+		"99999999999999999999 0",
+		"-1 0",
+		"2 2  1 2 3",
+	} {
+		if err := new(FmpzMat).UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText() %q expected error but got nil", text)
+		}
+		if err := json.Unmarshal([]byte(strconv.Quote(text)), new(FmpzMat)); err == nil {
+			t.Errorf("json.Unmarshal() %q expected error but got nil", text)
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		rows uint64
+		cols uint64
+	}{
+		{
+			name: "product wraps to zero",
+			rows: 1 << 32,
+			cols: 1 << 32,
+		},
+		{
+			name: "huge rows with no columns",
+			rows: 1 << 40,
+			cols: 0,
+		},
+		{
+			name: "rows overflowing an int",
+			rows: 1 << 63,
+			cols: 0,
+		},
+		{
+			name: "huge columns with no rows",
+			rows: 0,
+			cols: 1 << 40,
+		},
+	} {
+		buf := appendUvarint([]byte{marshalVersion}, tc.rows)
+		buf = appendUvarint(buf, tc.cols)
+		if err := new(FmpzMat).UnmarshalBinary(buf); err == nil {
+			t.Errorf("UnmarshalBinary() %s expected error but got nil", tc.name)
+		}
+	}
+}
+
+func TestGob(t *testing.T) {
+	type state struct {
+		N    *Fmpz
+		Q    *Fmpq
+		P    *FmpzPoly
+		MP   *FmpzModPoly
+		Mat  *FmpzMat
+		Note string
+	}
+
+	n, _ := new(Fmpz).SetString("-863653476616376575308866344984576466644942572246900013156919", 10)
+	want := state{
+		N:    n,
+		Q:    NewFmpq(-3, 2),
+		P:    NewFmpzPoly().SetCoeffUI(0, 1).SetCoeffUI(3, 5),
+		MP:   NewFmpzModPoly(NewFmpzModCtx(NewFmpz(6))).SetCoeffUI(0, 1).SetCoeffUI(1, 2),
+		Mat:  NewFmpzMat(2, 2).One(),
+		Note: "attack state",
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(want); err != nil {
+		t.Fatalf("gob Encode() got error when not expected: %v", err)
+	}
+
+	var got state
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatalf("gob Decode() got error when not expected: %v", err)
+	}
+
+	if got.N.Cmp(want.N) != 0 || got.Q.Cmp(want.Q) != 0 || !got.P.Equal(want.P) ||
+		got.MP.StringSimple() != want.MP.StringSimple() || got.Mat.String() != want.Mat.String() ||
+		got.Note != want.Note {
+		t.Errorf("gob round trip want / got mismatch: %+v / %+v", want, got)
+	}
+}
@@ -0,0 +1,148 @@
+package goflint
+
+/*
+#include <gmp.h>
+#include <flint/flint.h>
+#include <flint/fmpz.h>
+#include <flint/ulong_extras.h>
+
+// Seed both the FLINT word generator and the GMP state used for large random values so that a
+// seeded FlintRandT gives reproducible results.
+static void compat_flint_randseed(flint_rand_t state, ulong seed1, ulong seed2) {
+    mpz_t s;
+
+    mpz_init_set_ui(s, seed1);
+    mpz_mul_2exp(s, s, FLINT_BITS);
+    mpz_add_ui(s, s, seed2);
+
+    #if __FLINT_RELEASE >= 30000
+        // FLINT 3: Use new function names
+        flint_rand_set_seed(state, seed1, seed2);
+        _flint_rand_init_gmp_state(state);
+        gmp_randseed((__gmp_randstate_struct *) state->__gmp_state, s);
+    #else
+        // FLINT 2: Use old function names
+        flint_randseed(state, seed1, seed2);
+        _flint_rand_init_gmp(state);
+        gmp_randseed(state->gmp_state, s);
+    #endif
+
+    mpz_clear(s);
+}
+*/
+import "C"
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+)
+
+// NewFlintRandT allocates a new FlintRandT and returns it. With no seed the state starts from
+// FLINT's fixed default seed, one seed value is used for both halves of the seed and two seed
+// values are passed to Seed. More than two seed values cause a run-time panic.
+func NewFlintRandT(seed ...uint64) *FlintRandT {
+	r := new(FlintRandT)
+	r.flintRandTDoinit()
+	switch len(seed) {
+	case 0:
+	case 1:
+		r.Seed(seed[0], seed[0])
+	case 2:
+		r.Seed(seed[0], seed[1])
+	default:
+		panic("NewFlintRandT: at most two seed values are allowed")
+	}
+	return r
+}
+
+// Seed resets r to the state given by seed1 and seed2 and returns r. Two states seeded with the
+// same values produce the same sequence of random values.
+func (r *FlintRandT) Seed(seed1, seed2 uint64) *FlintRandT {
+	r.flintRandTDoinit()
+	C.compat_flint_randseed(&r.i[0], C.ulong(seed1), C.ulong(seed2))
+	return r
+}
+
+// SeedCrypto seeds r from crypto/rand and returns the seed values used so that the run can be
+// reproduced later with Seed.
+func (r *FlintRandT) SeedCrypto() (uint64, uint64, error) {
+	var buf [16]byte
+	if _, err := crand.Read(buf[:]); err != nil {
+		return 0, 0, fmt.Errorf("SeedCrypto: %v", err)
+	}
+	seed1 := binary.LittleEndian.Uint64(buf[:8])
+	seed2 := binary.LittleEndian.Uint64(buf[8:])
+	r.Seed(seed1, seed2)
+	return seed1, seed2, nil
+}
+
+// Uint64 returns a uniformly random 64 bit value from r.
+func (r *FlintRandT) Uint64() uint64 {
+	r.flintRandTDoinit()
+	return uint64(C.n_randlimb(&r.i[0]))
+}
+
+// flintSource adapts a FlintRandT to the math/rand.Source64 interface.
+type flintSource struct {
+	r *FlintRandT
+}
+
+// Source returns a math/rand.Source64 that draws from r, so that rand.New(r.Source()) gives a
+// math/rand generator sharing the state of r. Seeding the source calls r.Seed with the seed as
+// both seed values.
+func (r *FlintRandT) Source() rand.Source64 {
+	r.flintRandTDoinit()
+	return flintSource{r}
+}
+
+// Int63 implements math/rand.Source.
+func (s flintSource) Int63() int64 {
+	return int64(s.r.Uint64() >> 1)
+}
+
+// Uint64 implements math/rand.Source64.
+func (s flintSource) Uint64() uint64 {
+	return s.r.Uint64()
+}
+
+// Seed implements math/rand.Source.
+func (s flintSource) Seed(seed int64) {
+	s.r.Seed(uint64(seed), uint64(seed))
+}
+
+// RandBits sets z to a uniformly random integer between 0 and 2^bits - 1 inclusive and returns z.
+func (z *Fmpz) RandBits(state *FlintRandT, bits int) *Fmpz {
+	if bits < 0 {
+		panic("RandBits: negative bit count")
+	}
+	z.doinit()
+	state.flintRandTDoinit()
+	C.fmpz_urandomb(&z.i[0], &state.i[0], C.flint_bitcnt_t(bits))
+	return z
+}
+
+// RandTest sets z to a random signed integer whose absolute value has a random number of bits
+// between 0 and bits inclusive and returns z. The values are built from long runs of zero and
+// one bits, which makes them more likely than uniform values to trigger corner cases, and are
+// intended for testing.
+func (z *Fmpz) RandTest(state *FlintRandT, bits int) *Fmpz {
+	if bits < 0 {
+		panic("RandTest: negative bit count")
+	}
+	z.doinit()
+	state.flintRandTDoinit()
+	C.fmpz_randtest(&z.i[0], &state.i[0], C.flint_bitcnt_t(bits))
+	return z
+}
+
+// RandRange sets z to a uniformly random integer in the range [a, b) and returns z.
+// If b <= a a run-time panic occurs.
+func (z *Fmpz) RandRange(state *FlintRandT, a, b *Fmpz) *Fmpz {
+	if b.Cmp(a) <= 0 {
+		panic("RandRange: empty range")
+	}
+	lo := new(Fmpz).Set(a)
+	return z.Randm(state, new(Fmpz).Sub(b, a)).AddZ(lo)
+}
@@ -0,0 +1,172 @@
+package goflint
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestFlintRandTSeed(t *testing.T) {
+	large, _ := new(Fmpz).SetString("863653476616376575308866344984576466644942572246900013156919", 10)
+
+	draw := func(r *FlintRandT) []string {
+		var out []string
+		for i := 0; i < 5; i++ {
+			out = append(out, new(Fmpz).RandBits(r, 200).String())
+			out = append(out, new(Fmpz).Randm(r, large).String())
+			out = append(out, new(Fmpz).Randm(r, NewFmpz(1000)).String())
+		}
+		return out
+	}
+
+	for _, tc := range []struct {
+		name string
+		a    *FlintRandT
+		b    *FlintRandT
+		same bool
+	}{
+		{
+			name: "same single seed",
+			a:    NewFlintRandT(42),
+			b:    NewFlintRandT(42),
+			same: true,
+		},
+		{
+			name: "same seed pair",
+			a:    NewFlintRandT(1, 2),
+			b:    new(FlintRandT).Seed(1, 2),
+			same: true,
+		},
+		{
+			name: "default seed",
+			a:    NewFlintRandT(),
+			b:    new(FlintRandT),
+			same: true,
+		},
+		{
+			name: "different seeds",
+			a:    NewFlintRandT(42),
+			b:    NewFlintRandT(43),
+			same: false,
+		},
+	} {
+		a, b := draw(tc.a), draw(tc.b)
+		same := true
+		for i := range a {
+			if a[i] != b[i] {
+				same = false
+			}
+		}
+		if same != tc.same {
+			t.Errorf("Seed() %s want / got same sequence mismatch: %v / %v", tc.name, tc.same, same)
+		}
+	}
+}
+
+func TestFlintRandTReseed(t *testing.T) {
+	r := NewFlintRandT(7)
+	want := new(Fmpz).RandBits(r, 500)
+	r.Seed(7, 7)
+	if got := new(Fmpz).RandBits(r, 500); !got.Equals(want) {
+		t.Errorf("Seed() reseeding want / got mismatch: %v / %v", want, got)
+	}
+}
+
+func TestSeedCrypto(t *testing.T) {
+	r := new(FlintRandT)
+	s1, s2, err := r.SeedCrypto()
+	if err != nil {
+		t.Fatalf("SeedCrypto() got error when not expected: %v", err)
+	}
+
+	want := new(Fmpz).RandBits(r, 128)
+	if got := new(Fmpz).RandBits(NewFlintRandT(s1, s2), 128); !got.Equals(want) {
+		t.Errorf("SeedCrypto() replaying seed want / got mismatch: %v / %v", want, got)
+	}
+}
+
+func TestSource(t *testing.T) {
+	a := rand.New(NewFlintRandT(99).Source())
+	b := rand.New(NewFlintRandT(99).Source())
+	for i := 0; i < 10; i++ {
+		if x, y := a.Uint64(), b.Uint64(); x != y {
+			t.Fatalf("Source() Uint64 %d want / got mismatch: %d / %d", i, x, y)
+		}
+		if x := a.Int63(); x < 0 || x != b.Int63() {
+			t.Fatalf("Source() Int63 %d mismatch or negative: %d", i, x)
+		}
+	}
+
+	a.Seed(5)
+	b.Seed(5)
+	if x, y := a.Intn(1000000), b.Intn(1000000); x != y {
+		t.Errorf("Source() after Seed want / got mismatch: %d / %d", x, y)
+	}
+}
+
+func TestRandBits(t *testing.T) {
+	r := NewFlintRandT(1)
+	for _, bits := range []int{0, 1, 64, 65, 1000} {
+		for i := 0; i < 20; i++ {
+			got := new(Fmpz).RandBits(r, bits)
+			if got.Sign() < 0 || got.Bits() > bits {
+				t.Errorf("RandBits() %d bits returned out of range value %v", bits, got)
+			}
+		}
+	}
+}
+
+func TestRandTest(t *testing.T) {
+	r := NewFlintRandT(2)
+	negative := false
+	for i := 0; i < 100; i++ {
+		got := new(Fmpz).RandTest(r, 300)
+		if got.Bits() > 300 {
+			t.Errorf("RandTest() returned %v with more than 300 bits", got)
+		}
+		if got.Sign() < 0 {
+			negative = true
+		}
+	}
+	if !negative {
+		t.Error("RandTest() expected some negative values but got none")
+	}
+}
+
+func TestRandRange(t *testing.T) {
+	r := NewFlintRandT(3)
+	for _, tc := range []struct {
+		name string
+		a    string
+		b    string
+	}{
+		{
+			name: "small positive range",
+			a:    "10",
+			b:    "20",
+		},
+		{
+			name: "range spanning zero",
+			a:    "-5",
+			b:    "5",
+		},
+		{
+			name: "single value",
+			a:    "-7",
+			b:    "-6",
+		},
+		{
+			name: "large range",
+			a:    "1000000000000000000000",
+			b:    "863653476616376575308866344984576466644942572246900013156919",
+		},
+	} {
+		a, _ := new(Fmpz).SetString(tc.a, 10)
+		b, _ := new(Fmpz).SetString(tc.b, 10)
+		for i := 0; i < 50; i++ {
+			got := new(Fmpz).RandRange(r, a, b)
+			if got.Cmp(a) < 0 || got.Cmp(b) >= 0 {
+				t.Errorf("RandRange() %s returned %v outside [%v, %v)", tc.name, got, a, b)
+			}
+		}
+	}
+}
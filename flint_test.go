@@ -2,6 +2,8 @@ package goflint
 
 import (
 	"bytes"
+	"errors"
+	"math/big"
 	"testing"
 )
 
@@ -322,6 +324,126 @@ func TestGCD(t *testing.T) {
 	}
 }
 
+func TestXGCD(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		a    string
+		b    string
+		want string
+	}{
+		{
+			name: "small values",
+			a:    "240",
+			b:    "46",
+			want: "2",
+		},
+		{
+			name: "negative value",
+			a:    "-240",
+			b:    "46",
+			want: "2",
+		},
+		{
+			name: "coprime large values",
+			a:    "1000000016000000063",
+			b:    "998244373963131413",
+			want: "1",
+		},
+		{
+			name: "zero",
+			a:    "0",
+			b:    "-7",
+			want: "7",
+		},
+	} {
+		a, _ := new(Fmpz).SetString(tc.a, 10)
+		b, _ := new(Fmpz).SetString(tc.b, 10)
+
+		g, s, u := new(Fmpz).XGCD(a, b)
+		if g.String() != tc.want {
+			t.Errorf("XGCD() %s want / got mismatch: %s / %v", tc.name, tc.want, g)
+		}
+
+		bezout := new(Fmpz).Add(new(Fmpz).Mul(a, s), new(Fmpz).Mul(b, u))
+		if !bezout.Equals(g) {
+			t.Errorf("XGCD() %s a*s + b*t want / got mismatch: %v / %v", tc.name, g, bezout)
+		}
+	}
+}
+
+func TestModInverseErr(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		x       string
+		n       string
+		want    string
+		wantGCD string
+		wantErr bool
+	}{
+		{
+			name: "invertible",
+			x:    "3",
+			n:    "11",
+			want: "4",
+		},
+		{
+			name: "negative value",
+			x:    "-3",
+			n:    "11",
+			want: "7",
+		},
+		{
+			name:    "common factor",
+			x:       "6",
+			n:       "15",
+			wantGCD: "3",
+			wantErr: true,
+		},
+		{
+			name:    "factor of an RSA modulus",
+			x:       "2000000000078",
+			n:       "30000000001181000000000429",
+			wantGCD: "1000000000039",
+			wantErr: true,
+		},
+		{
+			name:    "zero modulus",
+			x:       "3",
+			n:       "0",
+			wantErr: true,
+		},
+	} {
+		x, _ := new(Fmpz).SetString(tc.x, 10)
+		n, _ := new(Fmpz).SetString(tc.n, 10)
+
+		z := NewFmpz(99)
+		got, err := z.ModInverseErr(x, n)
+		if !tc.wantErr {
+			if err != nil || got.String() != tc.want {
+				t.Errorf("ModInverseErr() %s want / got mismatch: %s / %v (err %v)", tc.name, tc.want, got, err)
+			}
+			continue
+		}
+
+		if err == nil {
+			t.Fatalf("ModInverseErr() %s expected error but got %v", tc.name, got)
+		}
+		if z.String() != "99" {
+			t.Errorf("ModInverseErr() %s modified z on failure: %v", tc.name, z)
+		}
+		var nie *NotInvertibleError
+		if !errors.As(err, &nie) {
+			if tc.wantGCD != "" {
+				t.Errorf("ModInverseErr() %s expected *NotInvertibleError but got %v", tc.name, err)
+			}
+			continue
+		}
+		if nie.GCD.String() != tc.wantGCD {
+			t.Errorf("ModInverseErr() %s gcd want / got mismatch: %s / %v", tc.name, tc.wantGCD, nie.GCD)
+		}
+	}
+}
+
 func TestSetString(t *testing.T) {
 	expected := NewFmpz(65293409233)
 	num, result := new(Fmpz).SetString("65293409233", 10)
@@ -354,6 +476,115 @@ func TestSetBytes(t *testing.T) {
 	}
 }
 
+func TestFillBytes(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		n       int64
+		size    int
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name: "padded to 5 bytes",
+			n:    8746238,
+			size: 5,
+			want: []byte{0x00, 0x00, 0x85, 0x74, 0xfe},
+		},
+		{
+			name: "exact fit",
+			n:    8746238,
+			size: 3,
+			want: []byte{0x85, 0x74, 0xfe},
+		},
+		{
+			name: "zero",
+			n:    0,
+			size: 2,
+			want: []byte{0x00, 0x00},
+		},
+		{
+			name:    "overflow",
+			n:       8746238,
+			size:    2,
+			wantErr: true,
+		},
+	} {
+		buf := []byte{0xff, 0xff, 0xff, 0xff, 0xff}[:tc.size]
+		got, err := NewFmpz(tc.n).FillBytes(buf)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("FillBytes() %s want error %v but got %v", tc.name, tc.wantErr, err)
+		}
+
+		if !tc.wantErr && !bytes.Equal(got, tc.want) {
+			t.Errorf("FillBytes() %s want / got mismatch: %x / %x", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestBytesLE(t *testing.T) {
+	a := NewFmpz(8746238)
+	b := []byte{0xfe, 0x74, 0x85}
+	c := a.BytesLE()
+
+	if !bytes.Equal(b, c) {
+		t.Errorf("Expected %x but got %x.", b, c)
+	}
+}
+
+func TestSetBytesLE(t *testing.T) {
+	a := []byte{0xfe, 0x74, 0x85}
+	b := new(Fmpz).SetBytesLE(a)
+	c := NewFmpz(8746238)
+
+	if b.Cmp(c) != 0 {
+		t.Errorf("Expected %v but got %v", c, b)
+	}
+}
+
+func TestSignedBytes(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		n    int64
+		want []byte
+	}{
+		{
+			name: "zero",
+			n:    0,
+			want: []byte{0x00},
+		},
+		{
+			name: "positive with top bit set needs a sign byte",
+			n:    128,
+			want: []byte{0x00, 0x80},
+		},
+		{
+			name: "minus one",
+			n:    -1,
+			want: []byte{0xff},
+		},
+		{
+			name: "minus 128 fits in one byte",
+			n:    -128,
+			want: []byte{0x80},
+		},
+		{
+			name: "minus 129 needs two bytes",
+			n:    -129,
+			want: []byte{0xff, 0x7f},
+		},
+	} {
+		got := NewFmpz(tc.n).SignedBytes()
+		if !bytes.Equal(got, tc.want) {
+			t.Errorf("SignedBytes() %s want / got mismatch: %x / %x", tc.name, tc.want, got)
+		}
+
+		back := new(Fmpz).SetSignedBytes(got)
+		if back.Cmp(NewFmpz(tc.n)) != 0 {
+			t.Errorf("SetSignedBytes() %s want / got mismatch: %d / %v", tc.name, tc.n, back)
+		}
+	}
+}
+
 func TestExp(t *testing.T) {
 	tt := []struct {
 		name string
@@ -641,6 +872,216 @@ func TestRoot(t *testing.T) {
 	}
 }
 
+func TestSqrtRem(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		n        string
+		wantRoot string
+		wantRem  string
+	}{
+		{
+			name:     "exact square",
+			n:        "4096",
+			wantRoot: "64",
+			wantRem:  "0",
+		},
+		{
+			name:     "not a square",
+			n:        "4100",
+			wantRoot: "64",
+			wantRem:  "4",
+		},
+		{
+			name:     "square of a large number",
+			n:        "745737328801536357332965474109720143987543709574332439199479589748294521504530177286985146946776321",
+			wantRoot: "27308191606211063007452829106911113837898400437889",
+			wantRem:  "0",
+		},
+	} {
+		n, _ := new(Fmpz).SetString(tc.n, 10)
+		r := new(Fmpz)
+		got, r := new(Fmpz).SqrtRem(n, r)
+
+		if got.String() != tc.wantRoot || r.String() != tc.wantRem {
+			t.Errorf("SqrtRem() %s want / got mismatch: (%s, %s) / (%v, %v)", tc.name, tc.wantRoot, tc.wantRem, got, r)
+		}
+	}
+}
+
+func TestRootRem(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		n        int64
+		r        int32
+		wantRoot int64
+		wantRem  int64
+	}{
+		{
+			name:     "cube root of 4096",
+			n:        4096,
+			r:        3,
+			wantRoot: 16,
+			wantRem:  0,
+		},
+		{
+			name:     "cube root of 4097",
+			n:        4097,
+			r:        3,
+			wantRoot: 16,
+			wantRem:  1,
+		},
+		{
+			name:     "cube root of -30",
+			n:        -30,
+			r:        3,
+			wantRoot: -3,
+			wantRem:  -3,
+		},
+	} {
+		r := new(Fmpz)
+		got, r := new(Fmpz).RootRem(NewFmpz(tc.n), tc.r, r)
+
+		if got.Cmp(NewFmpz(tc.wantRoot)) != 0 || r.Cmp(NewFmpz(tc.wantRem)) != 0 {
+			t.Errorf("RootRem() %s want / got mismatch: (%d, %d) / (%v, %v)", tc.name, tc.wantRoot, tc.wantRem, got, r)
+		}
+	}
+}
+
+func TestCeilSqrt(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		n    int64
+		want int64
+	}{
+		{
+			name: "exact square",
+			n:    4096,
+			want: 64,
+		},
+		{
+			name: "rounds up",
+			n:    4097,
+			want: 65,
+		},
+		{
+			name: "zero",
+			n:    0,
+			want: 0,
+		},
+	} {
+		got := new(Fmpz).CeilSqrt(NewFmpz(tc.n))
+
+		if got.Cmp(NewFmpz(tc.want)) != 0 {
+			t.Errorf("CeilSqrt() %s want / got mismatch: %d / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestCeilRoot(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		n    int64
+		r    int32
+		want int64
+	}{
+		{
+			name: "exact cube",
+			n:    4096,
+			r:    3,
+			want: 16,
+		},
+		{
+			name: "rounds up",
+			n:    4095,
+			r:    3,
+			want: 16,
+		},
+		{
+			name: "negative rounds towards zero",
+			n:    -30,
+			r:    3,
+			want: -3,
+		},
+	} {
+		got := new(Fmpz).CeilRoot(NewFmpz(tc.n), tc.r)
+
+		if got.Cmp(NewFmpz(tc.want)) != 0 {
+			t.Errorf("CeilRoot() %s want / got mismatch: %d / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestIsSquare(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		n    int64
+		want bool
+	}{
+		{
+			name: "square",
+			n:    4096,
+			want: true,
+		},
+		{
+			name: "not a square",
+			n:    4097,
+			want: false,
+		},
+		{
+			name: "negative",
+			n:    -4,
+			want: false,
+		},
+	} {
+		got := NewFmpz(tc.n).IsSquare()
+
+		if got != tc.want {
+			t.Errorf("IsSquare() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestIsPerfectPower(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		n        string
+		wantBase string
+		wantExp  int
+	}{
+		{
+			name:     "2^60",
+			n:        "1152921504606846976",
+			wantBase: "2",
+			wantExp:  60,
+		},
+		{
+			name:     "negative cube",
+			n:        "-27",
+			wantBase: "-3",
+			wantExp:  3,
+		},
+		{
+			name:     "large prime cubed",
+			n:        "644196820242402342388559779900701051852720917443756709637921397537138920320109474998146856836867576785657917679694677959481752984701182137884847615478869021352344194048936261699559",
+			wantBase: "863653476616376575308866344984576466644942572246900013156919",
+			wantExp:  3,
+		},
+		{
+			name:     "not a perfect power",
+			n:        "12",
+			wantBase: "<nil>",
+			wantExp:  0,
+		},
+	} {
+		n, _ := new(Fmpz).SetString(tc.n, 10)
+		b, k := n.IsPerfectPower()
+
+		if b.String() != tc.wantBase || k != tc.wantExp {
+			t.Errorf("IsPerfectPower() %s want / got mismatch: (%s, %d) / (%v, %d)", tc.name, tc.wantBase, tc.wantExp, b, k)
+		}
+	}
+}
+
 func TestIsStrongProbabPrime(t *testing.T) {
 	tt := []struct {
 		name    string
@@ -783,6 +1224,48 @@ func TestIsProbabPrime(t *testing.T) {
 	}
 }
 
+func TestIsPrime(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		test string
+		want int
+	}{
+		{
+			name: "large prime is proven prime",
+			test: "863653476616376575308866344984576466644942572246900013156919",
+			want: 1,
+		},
+		{
+			name: "mersenne prime 2^127-1",
+			test: "170141183460469231731687303715884105727",
+			want: 1,
+		},
+		{
+			name: "large composite is not prime",
+			test: "833810193564967701912362955539789451139872863794534923259743419423089229206473091408403560311191545764221310666338878019",
+			want: 0,
+		},
+		{
+			name: "one is not prime",
+			test: "1",
+			want: 0,
+		},
+		{
+			name: "negative prime is not prime",
+			test: "-7",
+			want: 0,
+		},
+	} {
+		p, ok := new(Fmpz).SetString(tc.test, 10)
+		if !ok {
+			t.Fatalf("IsPrime() %s failed converting test to Fmpz: %v", tc.name, tc.test)
+		}
+		if got := p.IsPrime(); got != tc.want {
+			t.Errorf("IsPrime() %s want / got mismatch: %d / %d", tc.name, tc.want, got)
+		}
+	}
+}
+
 func TestIsProbabPrimePseudosquare(t *testing.T) {
 	tt := []struct {
 		name    string
@@ -972,6 +1455,12 @@ func TestLsh(t *testing.T) {
 			shift: 3,
 			want:  NewFmpz(800),
 		},
+		{
+			name:  "left shift keeps the sign",
+			n:     NewFmpz(-100),
+			shift: 3,
+			want:  NewFmpz(-800),
+		},
 	} {
 		got := tc.n.Lsh(tc.shift)
 
@@ -1000,6 +1489,12 @@ func TestRsh(t *testing.T) {
 			shift: 3,
 			want:  NewFmpz(100),
 		},
+		{
+			name:  "right shift of a negative rounds to -infinity",
+			n:     NewFmpz(-7),
+			shift: 1,
+			want:  NewFmpz(-4),
+		},
 	} {
 		got := tc.n.Rsh(tc.shift)
 
@@ -1030,3 +1525,303 @@ func TestXor(t *testing.T) {
 		}
 	}
 }
+
+func TestLucasUV(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		p     int64
+		q     int64
+		n     int64
+		m     int64
+		wantU int64
+		wantV int64
+	}{
+		{
+			name:  "fibonacci and lucas numbers",
+			p:     1,
+			q:     -1,
+			n:     10,
+			m:     1000,
+			wantU: 55,
+			wantV: 123,
+		},
+		{
+			name:  "index zero",
+			p:     1,
+			q:     -1,
+			n:     0,
+			m:     1000,
+			wantU: 0,
+			wantV: 2,
+		},
+		{
+			name:  "mersenne and fermat numbers with an even modulus",
+			p:     3,
+			q:     2,
+			n:     20,
+			m:     1000000000,
+			wantU: 1048575,
+			wantV: 1048577,
+		},
+	} {
+		v := new(Fmpz)
+		u, v := new(Fmpz).LucasUV(v, NewFmpz(tc.p), NewFmpz(tc.q), NewFmpz(tc.n), NewFmpz(tc.m))
+
+		if u.Cmp(NewFmpz(tc.wantU)) != 0 || v.Cmp(NewFmpz(tc.wantV)) != 0 {
+			t.Errorf("LucasUV() %s want / got mismatch: (%d, %d) / (%v, %v)", tc.name, tc.wantU, tc.wantV, u, v)
+		}
+	}
+}
+
+func TestRshCeil(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		n     *Fmpz
+		shift int
+		want  *Fmpz
+	}{
+		{
+			name:  "right shift rounds up",
+			n:     NewFmpz(7),
+			shift: 1,
+			want:  NewFmpz(4),
+		},
+		{
+			name:  "right shift of a negative rounds up",
+			n:     NewFmpz(-7),
+			shift: 1,
+			want:  NewFmpz(-3),
+		},
+	} {
+		got := tc.n.RshCeil(tc.shift)
+
+		if got.Cmp(tc.want) != 0 {
+			t.Errorf("RshCeil() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestRshTrunc(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		n     *Fmpz
+		shift int
+		want  *Fmpz
+	}{
+		{
+			name:  "right shift rounds down",
+			n:     NewFmpz(7),
+			shift: 1,
+			want:  NewFmpz(3),
+		},
+		{
+			name:  "right shift of a negative rounds towards zero",
+			n:     NewFmpz(-7),
+			shift: 1,
+			want:  NewFmpz(-3),
+		},
+	} {
+		got := tc.n.RshTrunc(tc.shift)
+
+		if got.Cmp(tc.want) != 0 {
+			t.Errorf("RshTrunc() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestOr(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		a    *Fmpz
+		b    *Fmpz
+		want *Fmpz
+	}{
+		{
+			name: "41 | 20 = 61",
+			a:    NewFmpz(41),
+			b:    NewFmpz(20),
+			want: NewFmpz(61),
+		},
+		{
+			name: "-8 | 3 = -5",
+			a:    NewFmpz(-8),
+			b:    NewFmpz(3),
+			want: NewFmpz(-5),
+		},
+	} {
+		got := new(Fmpz).Or(tc.a, tc.b)
+
+		if got.Cmp(tc.want) != 0 {
+			t.Errorf("Or() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestNot(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		a    *Fmpz
+		want *Fmpz
+	}{
+		{
+			name: "^5 = -6",
+			a:    NewFmpz(5),
+			want: NewFmpz(-6),
+		},
+		{
+			name: "^-1 = 0",
+			a:    NewFmpz(-1),
+			want: NewFmpz(0),
+		},
+	} {
+		got := new(Fmpz).Not(tc.a)
+
+		if got.Cmp(tc.want) != 0 {
+			t.Errorf("Not() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestSetClrComBit(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		n    *Fmpz
+		f    func(z *Fmpz) *Fmpz
+		want string
+	}{
+		{
+			name: "set bit 100",
+			n:    NewFmpz(1),
+			f:    func(z *Fmpz) *Fmpz { return z.SetBit(100) },
+			want: "1267650600228229401496703205377",
+		},
+		{
+			name: "set bit 0 of a negative",
+			n:    NewFmpz(-8),
+			f:    func(z *Fmpz) *Fmpz { return z.SetBit(0) },
+			want: "-7",
+		},
+		{
+			name: "clear bit 2",
+			n:    NewFmpz(7),
+			f:    func(z *Fmpz) *Fmpz { return z.ClrBit(2) },
+			want: "3",
+		},
+		{
+			name: "clear bit 0 of a negative",
+			n:    NewFmpz(-1),
+			f:    func(z *Fmpz) *Fmpz { return z.ClrBit(0) },
+			want: "-2",
+		},
+		{
+			name: "complement bit 1",
+			n:    NewFmpz(5),
+			f:    func(z *Fmpz) *Fmpz { return z.ComBit(1) },
+			want: "7",
+		},
+	} {
+		got := tc.f(tc.n)
+
+		if got.String() != tc.want {
+			t.Errorf("%s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestPopCount(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		n    string
+		want int
+	}{
+		{
+			name: "zero",
+			n:    "0",
+			want: 0,
+		},
+		{
+			name: "2^100 - 1",
+			n:    "1267650600228229401496703205375",
+			want: 100,
+		},
+		{
+			name: "negative",
+			n:    "-3",
+			want: -1,
+		},
+	} {
+		n, _ := new(Fmpz).SetString(tc.n, 10)
+		got := n.PopCount()
+
+		if got != tc.want {
+			t.Errorf("PopCount() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestTrailingZeros(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		n    string
+		want int
+	}{
+		{
+			name: "zero",
+			n:    "0",
+			want: 0,
+		},
+		{
+			name: "2^100",
+			n:    "1267650600228229401496703205376",
+			want: 100,
+		},
+		{
+			name: "negative",
+			n:    "-24",
+			want: 3,
+		},
+	} {
+		n, _ := new(Fmpz).SetString(tc.n, 10)
+		got := n.TrailingZeros()
+
+		if got != tc.want {
+			t.Errorf("TrailingZeros() %s want / got mismatch: %v / %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestBigInt(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		n    string
+	}{
+		{
+			name: "zero",
+			n:    "0",
+		},
+		{
+			name: "small negative",
+			n:    "-42",
+		},
+		{
+			name: "multi limb positive",
+			n:    "833810193564967701912362955539789451139872863794534923259743419423089229206473091408403560311191545764221310666338878019",
+		},
+		{
+			name: "multi limb negative",
+			n:    "-863653476616376575308866344984576466644942572246900013156919",
+		},
+	} {
+		want, _ := new(big.Int).SetString(tc.n, 10)
+
+		z := new(Fmpz).SetBigInt(want)
+		if z.String() != tc.n {
+			t.Errorf("SetBigInt() %s want / got mismatch: %s / %v", tc.name, tc.n, z)
+		}
+
+		got := z.BigInt()
+		if got.Cmp(want) != 0 {
+			t.Errorf("BigInt() %s want / got mismatch: %v / %v", tc.name, want, got)
+		}
+	}
+}